@@ -1,6 +1,10 @@
 package ast
 
 import (
+	"net/netip"
+
+	"github.com/thenam153/conditions-go/cidr"
+	"github.com/thenam153/conditions-go/semver"
 	"github.com/thenam153/conditions-go/token"
 )
 
@@ -10,36 +14,162 @@ type Expr interface {
 
 type Node interface{}
 
+// Span is the byte offset range [Start, End) of a node in the parsed source.
+// Nodes built by the evaluator have an empty span.
+type Span struct {
+	Start int
+	End   int
+}
+
+func (s Span) Position() Span {
+	return s
+}
+
+// Positioned is implemented by every node having a Span
+type Positioned interface {
+	Position() Span
+}
+
+// Position returns the span of expr, or an empty span when it has none
+func Position(expr Expr) Span {
+	if p, ok := expr.(Positioned); ok {
+		return p.Position()
+	}
+	return Span{}
+}
+
 type BinaryExpr struct {
+	Span
 	LHS Expr
 	RHS Expr
 	OP  token.Token
 }
 
+// UnaryExpr is a prefix (EXISTS) or postfix (IS NULL) operator applied to Expr
+type UnaryExpr struct {
+	Span
+	OP   token.Token
+	Expr Expr
+}
+
+// RangeExpr is the right operand of BETWEEN: low AND high, the bounds are
+// included unless Exclusive
+type RangeExpr struct {
+	Span
+	Low       Expr
+	High      Expr
+	Exclusive bool
+}
+
+// QuantifierExpr tests Body against the elements of the list Source, each
+// bound to Var in turn: ANY x IN [items] : x.qty > 0. OP is ANY, ALL or NONE.
+type QuantifierExpr struct {
+	Span
+	OP     token.Token
+	Var    string
+	Source Expr
+	Body   Expr
+}
+
+// BoundRef references the element bound by a quantifier, Path is the
+// dotted path into it: x.qty has Name "x" and Path "qty"
+type BoundRef struct {
+	Span
+	Name string
+	Path string
+}
+
+// CallExpr is a call of a built-in function, e.g. now() or hour([created])
+type CallExpr struct {
+	Span
+	Name string
+	Args []Expr
+}
+
+// Functions maps the name of every built-in function to its arity
+var Functions = map[string]int{
+	"now":       0,
+	"dayOfWeek": 1,
+	"hour":      1,
+	"semver":    1,
+	"ip":        1,
+}
+
 type ParenExpr struct {
+	Span
 	Expr Expr
 }
 
 type VarRef struct {
+	Span
 	Value string
 }
 
 type StringLiteral struct {
+	Span
 	Value string
 }
 
 type NumberLiteral struct {
+	Span
 	Value float64
 }
 
 type BooleanLiteral struct {
+	Span
 	Value bool
 }
 
+// NullLiteral is the NULL literal, also the value of a nil argument
+type NullLiteral struct {
+	Span
+}
+
 type SliceStringLiteral struct {
+	Span
 	Value []string
 }
 
 type SliceNumberLiteral struct {
+	Span
 	Value []float64
 }
+
+// ListLiteral is an array whose elements are not all strings or all
+// numbers, e.g. [1, "a", TRUE], [[1, 2], [3]] or [], elements keep their type
+type ListLiteral struct {
+	Span
+	Value []Expr
+}
+
+// ObjectLiteral is a JSON object value of an argument or JQ result, it has
+// no source syntax
+type ObjectLiteral struct {
+	Span
+	Value map[string]any
+}
+
+// VersionLiteral is a semantic version, the value of semver()
+type VersionLiteral struct {
+	Span
+	Value semver.Version
+}
+
+// IPLiteral is an IPv4 or IPv6 address, the value of ip()
+type IPLiteral struct {
+	Span
+	Value netip.Addr
+}
+
+// ConstraintLiteral is the version range of SATISFIES, parsed from a string
+type ConstraintLiteral struct {
+	Span
+	Value *semver.Constraint
+}
+
+// CIDRLiteral is the set of prefixes of IN CIDR, parsed from a string or a
+// string array
+type CIDRLiteral struct {
+	Span
+	Value *cidr.Set
+}
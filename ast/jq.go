@@ -5,15 +5,27 @@ import "github.com/itchyny/gojq"
 type JQMode int
 
 const (
+	// JQFirst is the first result, the default mode
 	JQFirst JQMode = iota
+	// JQLast is the last result
 	JQLast
+	// JQArray is the list of all results
 	JQArray
+	// JQAny is TRUE when a result is neither FALSE nor NULL
+	JQAny
+	// JQAll is TRUE when no result is FALSE or NULL
+	JQAll
+	// JQCount is the number of results
+	JQCount
 )
 
 var JQModes = map[string]JQMode{
 	"first": JQFirst,
 	"last":  JQLast,
 	"array": JQArray,
+	"any":   JQAny,
+	"all":   JQAll,
+	"count": JQCount,
 }
 
 type JQMsg struct {
@@ -22,7 +34,12 @@ type JQMsg struct {
 }
 
 type JQRef struct {
+	Span
 	Value string
 	Query *gojq.Query
-	Mode  string
+	// Code is Query compiled by the parser, Variables are the names of its
+	// variables in the order their values are given to Code
+	Code      *gojq.Code
+	Variables []string
+	Mode      string
 }
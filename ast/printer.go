@@ -0,0 +1,178 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String returns the source representation of an expression
+func String(expr Expr) string {
+	if s, ok := expr.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%v", expr)
+}
+
+func (e *BinaryExpr) String() string {
+	return fmt.Sprintf("%v %v %v", String(e.LHS), e.OP, String(e.RHS))
+}
+
+func (e *UnaryExpr) String() string {
+	if e.OP.IsPostfix() {
+		return String(e.Expr) + " " + e.OP.String()
+	}
+	return e.OP.String() + " " + String(e.Expr)
+}
+
+func (e *RangeExpr) String() string {
+	s := String(e.Low) + " AND " + String(e.High)
+	if e.Exclusive {
+		s += " EXCLUSIVE"
+	}
+	return s
+}
+
+func (e *QuantifierExpr) String() string {
+	return fmt.Sprintf("%v %v IN %v : %v", e.OP, e.Var, String(e.Source), String(e.Body))
+}
+
+func (e *BoundRef) String() string {
+	if e.Path == "" {
+		return e.Name
+	}
+	return e.Name + "." + e.Path
+}
+
+func (e *ParenExpr) String() string {
+	return "(" + String(e.Expr) + ")"
+}
+
+// Nested references are stored as "foo.bar" and printed as [foo][bar]
+func (e *VarRef) String() string {
+	if strings.HasPrefix(e.Value, "$") {
+		return e.Value
+	}
+	return "[" + strings.ReplaceAll(e.Value, ".", "][") + "]"
+}
+
+// Values are kept unescaped, a value holding a double quote comes from a
+// /regex/ literal and is printed back as one
+func (e *StringLiteral) String() string {
+	if !containsUnescaped(e.Value, '"') {
+		return `"` + e.Value + `"`
+	}
+	if !containsUnescaped(e.Value, '/') {
+		return "/" + e.Value + "/"
+	}
+	return `"` + e.Value + `"`
+}
+
+// Report whether s contains c or a newline not escaped by a backslash, or
+// ends with a dangling backslash
+func containsUnescaped(s string, c rune) bool {
+	escaped := false
+	for _, r := range s {
+		switch {
+		case r == '\n' && c == '"':
+			return true
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == c:
+			return true
+		}
+	}
+	return escaped
+}
+
+func (e *NumberLiteral) String() string {
+	return strconv.FormatFloat(e.Value, 'g', -1, 64)
+}
+
+func (e *BooleanLiteral) String() string {
+	if e.Value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (e *NullLiteral) String() string {
+	return "NULL"
+}
+
+func (e *SliceStringLiteral) String() string {
+	bytes, _ := json.Marshal(e.Value)
+	return string(bytes)
+}
+
+func (e *SliceNumberLiteral) String() string {
+	bytes, _ := json.Marshal(e.Value)
+	return string(bytes)
+}
+
+func (e *ListLiteral) String() string {
+	elems := make([]string, len(e.Value))
+	for i, elem := range e.Value {
+		// Strings of arrays are JSON strings, as in SliceStringLiteral
+		if s, ok := elem.(*StringLiteral); ok {
+			bytes, _ := json.Marshal(s.Value)
+			elems[i] = string(bytes)
+			continue
+		}
+		elems[i] = String(elem)
+	}
+	return "[" + strings.Join(elems, ", ") + "]"
+}
+
+func (e *ObjectLiteral) String() string {
+	bytes, _ := json.Marshal(e.Value)
+	return string(bytes)
+}
+
+func (e *TimeLiteral) String() string {
+	return `t"` + e.Value.Format(time.RFC3339Nano) + `"`
+}
+
+func (e *DurationLiteral) String() string {
+	return FormatDuration(e.Value)
+}
+
+func (e *CallExpr) String() string {
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = String(arg)
+	}
+	return e.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (e *VersionLiteral) String() string {
+	return `semver("` + e.Value.String() + `")`
+}
+
+func (e *IPLiteral) String() string {
+	return `ip("` + e.Value.String() + `")`
+}
+
+func (e *ConstraintLiteral) String() string {
+	return (&StringLiteral{Value: e.Value.String()}).String()
+}
+
+func (e *CIDRLiteral) String() string {
+	bytes, _ := json.Marshal(e.Value.Strings())
+	return string(bytes)
+}
+
+func (e *JQRef) String() string {
+	query := ""
+	if e.Query != nil {
+		query = e.Query.String()
+	}
+	if e.Mode == "" {
+		return "$jq(" + query + ")"
+	}
+	return "$jq[" + e.Mode + "](" + query + ")"
+}
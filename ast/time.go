@@ -0,0 +1,149 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	lerrors "github.com/thenam153/conditions-go/errors"
+)
+
+// TimeLiteral is a point in time, written t"2026-01-01T00:00:00Z"
+type TimeLiteral struct {
+	Span
+	Value time.Time
+}
+
+// DurationLiteral is a length of time, written 7d, 90m or 1h30m
+type DurationLiteral struct {
+	Span
+	Value time.Duration
+}
+
+// Time layouts accepted by time literals, the date only layout is UTC
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02"}
+
+// ParseTime parses the value of a time literal
+func ParseTime(s string) (time.Time, error) {
+	var err error
+	for _, layout := range timeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, lerrors.NewWrap("Invalid time, expected RFC3339", err)
+}
+
+// Units of duration literals, a unit is matched before its prefixes
+var durationUnits = []struct {
+	name  string
+	value time.Duration
+}{
+	{"w", 7 * 24 * time.Hour},
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+	{"µs", time.Microsecond},
+	{"ns", time.Nanosecond},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+// Units of printed durations, largest first
+var formatUnits = []struct {
+	name  string
+	value time.Duration
+}{
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+	{"ns", time.Nanosecond},
+}
+
+// ParseDuration parses a duration literal, a sequence of numbers each
+// followed by a unit: w, d, h, m, s, ms, us or ns, e.g. 1d12h or -1.5h.
+// Whole numbers are exact, down to the nanosecond.
+func ParseDuration(s string) (time.Duration, error) {
+	var (
+		// Magnitude in nanoseconds
+		total uint64
+		neg   = strings.HasPrefix(s, "-")
+	)
+	rest := strings.TrimPrefix(s, "-")
+	if rest == "" {
+		return 0, lerrors.Newf("Invalid duration %q", s)
+	}
+	outOfRange := lerrors.Newf("Invalid duration %q, out of range", s)
+	for rest != "" {
+		i := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i <= 0 || rest[:i] == "." {
+			return 0, lerrors.Newf("Invalid duration %q, expected number", s)
+		}
+		whole, frac, _ := strings.Cut(rest[:i], ".")
+		rest = rest[i:]
+		var unit time.Duration
+		for _, u := range durationUnits {
+			if strings.HasPrefix(rest, u.name) {
+				unit, rest = u.value, rest[len(u.name):]
+				break
+			}
+		}
+		if unit == 0 {
+			return 0, lerrors.Newf("Invalid duration %q, unknown unit", s)
+		}
+		var n uint64
+		if whole != "" {
+			var err error
+			if n, err = strconv.ParseUint(whole, 10, 64); err != nil || n > 1<<63/uint64(unit) {
+				return 0, outOfRange
+			}
+		}
+		part := n * uint64(unit)
+		if frac != "" {
+			f, err := strconv.ParseFloat("0."+frac, 64)
+			if err != nil {
+				return 0, lerrors.NewWrap("Invalid duration "+strconv.Quote(s), err)
+			}
+			part += uint64(f * float64(unit))
+		}
+		if part > 1<<63-total {
+			return 0, outOfRange
+		}
+		total += part
+	}
+	// The minimum duration has no positive counterpart
+	if !neg && total == 1<<63 {
+		return 0, outOfRange
+	}
+	d := time.Duration(total)
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// FormatDuration returns the literal of d, ParseDuration reads it back
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+	var sb strings.Builder
+	// Unsigned so that the minimum duration can be negated
+	u := uint64(d)
+	if d < 0 {
+		sb.WriteByte('-')
+		u = -u
+	}
+	for _, unit := range formatUnits {
+		if n := u / uint64(unit.value); n > 0 {
+			sb.WriteString(strconv.FormatUint(n, 10) + unit.name)
+			u -= n * uint64(unit.value)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+package ast_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/thenam153/conditions-go/ast"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{in: "0s", want: 0},
+		{in: "90m", want: 90 * time.Minute},
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: "1.5h", want: 90 * time.Minute},
+		{in: "-1.5h", want: -90 * time.Minute},
+		{in: "2w1d", want: 15 * 24 * time.Hour},
+		{in: "1ms", want: time.Millisecond},
+		{in: "1us", want: time.Microsecond},
+		{in: "1µs", want: time.Microsecond},
+		{in: "1ns", want: time.Nanosecond},
+		{in: "1m1ms", want: time.Minute + time.Millisecond},
+		{in: "", err: true},
+		{in: "-", err: true},
+		{in: "1", err: true},
+		{in: "h", err: true},
+		{in: "1x", err: true},
+		{in: "1.2.3h", err: true},
+		{in: "1h-1m", err: true},
+		{in: "300000000h", err: true},
+		{in: "106751d23h47m16s854ms775us807ns", want: math.MaxInt64},
+		{in: "106751d23h47m16s854ms775us808ns", err: true},
+		{in: "-106751d23h47m16s854ms775us808ns", want: math.MinInt64},
+		{in: "9223372036854775807ns9223372036854775807ns", err: true},
+		{in: "99999999999999999999ns", err: true},
+		{in: ".5h", want: 30 * time.Minute},
+		{in: "0.1s", want: 100 * time.Millisecond},
+		{in: ".h", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ast.ParseDuration(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseDuration(%q): got error %v, want error %v", tt.in, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{90 * time.Minute, "1h30m"},
+		{-90 * time.Minute, "-1h30m"},
+		{15 * 24 * time.Hour, "15d"},
+		{time.Hour + time.Nanosecond, "1h1ns"},
+		{1500 * time.Microsecond, "1ms500us"},
+	}
+	for _, tt := range tests {
+		if got := ast.FormatDuration(tt.in); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	// Round trip, the extremes included
+	for _, d := range []time.Duration{
+		0, 1, -1, time.Second, 25*time.Hour + 59*time.Second, -36 * time.Hour,
+		math.MaxInt64, math.MinInt64, math.MinInt64 + 1,
+	} {
+		s := ast.FormatDuration(d)
+		got, err := ast.ParseDuration(s)
+		if err != nil || got != d {
+			t.Errorf("ParseDuration(FormatDuration(%d)) = %d, %v via %q", d, got, err, s)
+		}
+	}
+}
@@ -0,0 +1,619 @@
+package casegen
+
+import (
+	"fmt"
+	"math"
+	"net/netip"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/cidr"
+	"github.com/thenam153/conditions-go/condtest"
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/evaluator"
+	"github.com/thenam153/conditions-go/internal/like"
+	"github.com/thenam153/conditions-go/semver"
+	"github.com/thenam153/conditions-go/token"
+)
+
+// requirement assigns fields so that a comparison leaf gets one outcome
+type requirement map[string]any
+
+// Generate returns a minimal set of args covering true and false of every
+// comparison leaf of expr, plus boundary values (c-step, c, c+step) of
+// numeric ordering constants. Requirements of different fields are packed
+// into the same args, fields left free get their first generated value.
+// A quantifier over a field gets an empty list and single element lists
+// covering the comparisons of the element with literals in its condition.
+// Comparisons without a field (e.g. $jq) or of the element with a field are
+// not covered.
+func Generate(expr ast.Expr, schema Schema) ([]map[string]any, error) {
+	if expr == nil {
+		return nil, lerrors.New("Expression must be not nil")
+	}
+	g := &generator{schema: schema, defaults: map[string]any{}}
+	if err := g.walk(expr); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := schema[name]
+		if _, ok := g.defaults[name]; !ok && len(f.Values) > 0 {
+			g.defaults[name] = f.Values[0]
+		}
+		for _, v := range f.Values {
+			g.add(requirement{name: v})
+		}
+	}
+	cases := []map[string]any{}
+	for _, req := range g.reqs {
+		placed := false
+		for _, c := range cases {
+			if compatible(c, req) {
+				for k, v := range req {
+					c[k] = v
+				}
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			c := make(map[string]any, len(req))
+			for k, v := range req {
+				c[k] = v
+			}
+			cases = append(cases, c)
+		}
+	}
+	if len(cases) == 0 {
+		cases = append(cases, map[string]any{})
+	}
+	for _, c := range cases {
+		for name, v := range g.defaults {
+			if _, ok := c[name]; !ok {
+				c[name] = v
+			}
+		}
+	}
+	return cases, nil
+}
+
+// Cases generates args for expr and evaluates them, the result is recorded
+// as the expected outcome. The cases are coverage inputs, not oracles: they
+// record what expr does, right or wrong, and must be reviewed before they
+// are fed to condtest.
+func Cases(expr ast.Expr, schema Schema) ([]condtest.Case, error) {
+	args, err := Generate(expr, schema)
+	if err != nil {
+		return nil, err
+	}
+	cases := make([]condtest.Case, 0, len(args))
+	for i, a := range args {
+		c := condtest.Case{Name: fmt.Sprintf("generated %d", i), Args: a}
+		if got, err := evaluator.Evaluate(expr, a); err != nil {
+			msg := ""
+			c.Error = &msg
+		} else {
+			c.Expect = &got
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+func compatible(c map[string]any, req requirement) bool {
+	for k, v := range req {
+		if cv, ok := c[k]; ok && !reflect.DeepEqual(cv, v) {
+			return false
+		}
+	}
+	return true
+}
+
+type generator struct {
+	schema   Schema
+	reqs     []requirement
+	defaults map[string]any
+	// Variable of the quantifier whose elements are generated, its paths
+	// are the fields
+	bound string
+}
+
+// Field name of a reference, the path for a reference to the generated
+// element
+func (g *generator) name(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.VarRef:
+		return e.Value, g.bound == ""
+	case *ast.BoundRef:
+		return e.Path, g.bound != "" && e.Name == g.bound
+	}
+	return "", false
+}
+
+func (g *generator) add(reqs ...requirement) {
+	for _, req := range reqs {
+		for k, v := range req {
+			if _, ok := g.defaults[k]; !ok {
+				g.defaults[k] = v
+			}
+		}
+		g.reqs = append(g.reqs, req)
+	}
+}
+
+func (g *generator) walk(expr ast.Expr) error {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return g.walk(e.Expr)
+	case *ast.VarRef, *ast.BoundRef:
+		// Boolean field used as operand of a logical operator
+		if name, ok := g.name(e); ok {
+			g.add(requirement{name: true}, requirement{name: false})
+		}
+	case *ast.BinaryExpr:
+		if e.OP.IsLogical() {
+			if err := g.walk(e.LHS); err != nil {
+				return err
+			}
+			return g.walk(e.RHS)
+		}
+		return g.leaf(e)
+	case *ast.UnaryExpr:
+		// Absent fields cannot be required, EXISTS gets no case of its own
+		if name, ok := g.name(unparen(e.Expr)); ok && e.OP.IsPostfix() {
+			values, _ := g.values(name, token.EQ, &ast.NullLiteral{})
+			for _, value := range values {
+				g.add(requirement{name: value})
+			}
+		}
+	case *ast.QuantifierExpr:
+		// Fields of the condition, then the lists deciding it
+		if err := g.walk(e.Body); err != nil {
+			return err
+		}
+		name, ok := g.name(unparen(e.Source))
+		if !ok {
+			return nil
+		}
+		elements, err := g.elements(e)
+		if err != nil {
+			return err
+		}
+		g.add(requirement{name: []any{}})
+		for _, element := range elements {
+			g.add(requirement{name: []any{element}})
+		}
+	}
+	return nil
+}
+
+// Elements of the list of quantifier e covering the comparisons of its
+// condition on the element, e.g. {"qty": 0} and {"qty": 1} for x.qty > 0.
+// Paths left free get their first generated value.
+func (g *generator) elements(e *ast.QuantifierExpr) ([]any, error) {
+	sub := &generator{defaults: map[string]any{}, bound: e.Var}
+	if err := sub.walk(e.Body); err != nil {
+		return nil, err
+	}
+	elements := make([]any, 0, len(sub.reqs))
+	for _, req := range sub.reqs {
+		// The element itself, e.g. x > 1
+		if v, ok := req[""]; ok {
+			elements = append(elements, v)
+			continue
+		}
+		element := make(map[string]any, len(sub.defaults))
+		for path, v := range sub.defaults {
+			if path != "" {
+				element[path] = v
+			}
+		}
+		for path, v := range req {
+			element[path] = v
+		}
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
+
+func (g *generator) leaf(e *ast.BinaryExpr) error {
+	l, r, op := unparen(e.LHS), unparen(e.RHS), e.OP
+	lname, lok := g.name(l)
+	rname, rok := g.name(r)
+	switch {
+	case lok && rok:
+		g.add(g.pair(lname, rname, op)...)
+	case lok:
+		values, err := g.values(lname, op, r)
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			g.add(requirement{lname: v})
+		}
+	case rok:
+		switch op {
+		case token.IN, token.NOTIN:
+			for _, v := range g.members(rname, l) {
+				g.add(requirement{rname: v})
+			}
+			return nil
+		case token.EREG, token.NEREG:
+			// Pattern read from args, nothing to derive
+			return nil
+		}
+		if stringOperator(op) || op == token.SATISFIES || op == token.NOTSATISFIES ||
+			op == token.INCIDR || op == token.NOTINCIDR {
+			return nil
+		}
+		values, err := g.values(rname, flip(op), l)
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			g.add(requirement{rname: v})
+		}
+	}
+	return nil
+}
+
+// Values of field compared with literal lit so that every outcome of op is seen
+func (g *generator) values(name string, op token.Token, lit ast.Expr) ([]any, error) {
+	f := g.schema.field(name)
+	switch n := lit.(type) {
+	case *ast.NumberLiteral:
+		c, step := n.Value, f.step()
+		switch op {
+		case token.LT, token.LTE, token.GT, token.GTE:
+			return g.numbers(f, c-step, c, c+step), nil
+		default:
+			return g.numbers(f, c, c+step), nil
+		}
+	case *ast.StringLiteral:
+		switch op {
+		case token.EREG, token.NEREG:
+			return regexValues(n.Value)
+		case token.LIKE, token.NOTLIKE:
+			return regexValues(like.Regex(n.Value))
+		case token.ILIKE, token.NOTILIKE:
+			return regexValues("(?i)" + like.Regex(n.Value))
+		case token.SATISFIES, token.NOTSATISFIES:
+			c, err := semver.ParseConstraint(n.Value)
+			if err != nil {
+				return nil, lerrors.NewWrap("Cannot parse version range", err)
+			}
+			return versionValues(c), nil
+		case token.CONTAINS, token.NOTCONTAINS, token.ICONTAINS, token.NOTICONTAINS,
+			token.STARTSWITH, token.NOTSTARTSWITH, token.ENDSWITH, token.NOTENDSWITH:
+			// The empty string contains nothing but the empty string
+			return []any{n.Value, ""}, nil
+		case token.LT, token.LTE, token.GT, token.GTE:
+			// A proper prefix sorts before the value
+			if n.Value == "" {
+				return []any{n.Value, n.Value + "_"}, nil
+			}
+			_, size := utf8.DecodeLastRuneInString(n.Value)
+			return []any{n.Value[:len(n.Value)-size], n.Value, n.Value + "_"}, nil
+		default:
+			return []any{n.Value, n.Value + "_"}, nil
+		}
+	case *ast.BooleanLiteral:
+		return []any{n.Value, !n.Value}, nil
+	case *ast.ConstraintLiteral:
+		return versionValues(n.Value), nil
+	case *ast.CIDRLiteral:
+		return addressValues(n.Value), nil
+	case *ast.TimeLiteral:
+		switch op {
+		case token.LT, token.LTE, token.GT, token.GTE:
+			return []any{n.Value.Add(-time.Second), n.Value, n.Value.Add(time.Second)}, nil
+		default:
+			return []any{n.Value, n.Value.Add(time.Second)}, nil
+		}
+	case *ast.DurationLiteral:
+		switch op {
+		case token.LT, token.LTE, token.GT, token.GTE:
+			return []any{n.Value - time.Second, n.Value, n.Value + time.Second}, nil
+		default:
+			return []any{n.Value, n.Value + time.Second}, nil
+		}
+	case *ast.NullLiteral:
+		return []any{nil, g.sample(f)}, nil
+	case *ast.RangeExpr:
+		// Each side of both bounds, bounds which are not literals give nothing
+		low, err := g.values(name, token.GTE, unparen(n.Low))
+		if err != nil {
+			return nil, err
+		}
+		high, err := g.values(name, token.LTE, unparen(n.High))
+		if err != nil {
+			return nil, err
+		}
+		return append(low, high...), nil
+	case *ast.SliceStringLiteral:
+		if len(n.Value) == 0 {
+			return nil, nil
+		}
+		other := n.Value[0] + "_"
+		for contains(n.Value, other) {
+			other += "_"
+		}
+		if setOperator(op) {
+			// The whole array, one member, a non-member and nothing
+			return []any{n.Value, n.Value[:1], []string{other}, []string{}}, nil
+		}
+		return []any{n.Value[0], other}, nil
+	case *ast.SliceNumberLiteral:
+		if len(n.Value) == 0 {
+			return nil, nil
+		}
+		max := n.Value[0]
+		for _, v := range n.Value {
+			max = math.Max(max, v)
+		}
+		if setOperator(op) {
+			return []any{n.Value, n.Value[:1], []float64{max + f.step()}, []float64{}}, nil
+		}
+		return g.numbers(f, n.Value[0], max+f.step()), nil
+	case *ast.ListLiteral:
+		if setOperator(op) {
+			return nil, nil
+		}
+		// Each scalar element and a value next to it, nested arrays give
+		// nothing
+		var values []any
+		for _, elem := range n.Value {
+			switch elem.(type) {
+			case *ast.SliceStringLiteral, *ast.SliceNumberLiteral, *ast.ListLiteral:
+				continue
+			}
+			v, err := g.values(name, token.EQ, elem)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v...)
+		}
+		return values, nil
+	}
+	return nil, nil
+}
+
+// Slice values of field containing and not containing literal lit
+func (g *generator) members(name string, lit ast.Expr) []any {
+	f := g.schema.field(name)
+	switch n := lit.(type) {
+	case *ast.StringLiteral:
+		return []any{[]string{n.Value}, []string{}}
+	case *ast.NumberLiteral:
+		if f.Type == Integer {
+			return []any{[]int{int(n.Value)}, []int{}}
+		}
+		return []any{[]float64{n.Value}, []float64{}}
+	}
+	return nil
+}
+
+// Equal and different values for two fields compared with each other
+func (g *generator) pair(l, r string, op token.Token) []requirement {
+	switch g.schema.field(l).Type {
+	case String:
+		return []requirement{{l: "a", r: "a"}, {l: "a", r: "b"}, {l: "b", r: "a"}}
+	case Bool:
+		return []requirement{{l: true, r: true}, {l: true, r: false}}
+	}
+	switch op {
+	case token.LT, token.LTE, token.GT, token.GTE:
+		f := g.schema.field(l)
+		n := g.numbers(f, 0, 1)
+		return []requirement{{l: n[0], r: n[1]}, {l: n[1], r: n[1]}, {l: n[1], r: n[0]}}
+	case token.EQ, token.NEQ:
+		f := g.schema.field(l)
+		n := g.numbers(f, 0, 1)
+		return []requirement{{l: n[0], r: n[0]}, {l: n[0], r: n[1]}}
+	}
+	return nil
+}
+
+// A non-null value of field
+func (g *generator) sample(f Field) any {
+	if len(f.Values) > 0 && f.Values[0] != nil {
+		return f.Values[0]
+	}
+	switch f.Type {
+	case Number:
+		return 0.0
+	case Integer:
+		return 0
+	case Bool:
+		return false
+	case SliceString:
+		return []string{}
+	case SliceNumber:
+		return []float64{}
+	}
+	return ""
+}
+
+func (g *generator) numbers(f Field, values ...float64) []any {
+	result := make([]any, 0, len(values))
+	for _, v := range values {
+		if f.Type == Integer {
+			result = append(result, int(v))
+		} else {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// A string matched by pattern and one that is not
+func regexValues(pattern string) ([]any, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, lerrors.NewWrap("Cannot compile regex", err)
+	}
+	values := []any{}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, lerrors.NewWrap("Cannot parse regex", err)
+	}
+	match := sampleRegex(parsed.Simplify())
+	if re.MatchString(match) {
+		values = append(values, match)
+	}
+	for _, candidate := range []string{"", "#", match + "#", "#" + match, "\n"} {
+		if !re.MatchString(candidate) {
+			values = append(values, candidate)
+			break
+		}
+	}
+	return values, nil
+}
+
+// Build a string matching the regex, choosing the shortest branch everywhere
+func sampleRegex(re *syntax.Regexp) string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return string(re.Rune)
+	case syntax.OpCharClass:
+		if len(re.Rune) > 0 {
+			return string(re.Rune[0])
+		}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return "a"
+	case syntax.OpCapture, syntax.OpPlus:
+		return sampleRegex(re.Sub[0])
+	case syntax.OpRepeat:
+		s := ""
+		for i := 0; i < re.Min; i++ {
+			s += sampleRegex(re.Sub[0])
+		}
+		return s
+	case syntax.OpConcat:
+		s := ""
+		for _, sub := range re.Sub {
+			s += sampleRegex(sub)
+		}
+		return s
+	case syntax.OpAlternate:
+		return sampleRegex(re.Sub[0])
+	}
+	return ""
+}
+
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		p, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = p.Expr
+	}
+}
+
+// String matching operators, nothing is derived for a field on their RHS
+func stringOperator(op token.Token) bool {
+	switch op {
+	case token.CONTAINS, token.NOTCONTAINS, token.ICONTAINS, token.NOTICONTAINS,
+		token.STARTSWITH, token.NOTSTARTSWITH, token.ENDSWITH, token.NOTENDSWITH,
+		token.LIKE, token.NOTLIKE, token.ILIKE, token.NOTILIKE:
+		return true
+	}
+	return false
+}
+
+// Versions at and next to the bounds of a version range, some inside and
+// some outside of it
+func versionValues(c *semver.Constraint) []any {
+	seen := map[string]bool{}
+	values := []any{}
+	for _, v := range append(c.Versions(), semver.Version{}) {
+		next := semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+		v.Pre, v.Build = nil, ""
+		for _, s := range []string{v.String(), next.String()} {
+			if !seen[s] {
+				seen[s] = true
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+// The first address of a prefix of set and an address outside of the set
+func addressValues(set *cidr.Set) []any {
+	prefixes := set.Prefixes()
+	if len(prefixes) == 0 {
+		return nil
+	}
+	values := []any{prefixes[0].Addr().String()}
+	candidates := []netip.Addr{netip.IPv4Unspecified(), netip.MustParseAddr("255.255.255.255"), netip.IPv6Unspecified()}
+	for _, p := range prefixes {
+		// The address after the last one of the prefix
+		bytes := p.Addr().AsSlice()
+		for i := p.Bits(); i < len(bytes)*8; i++ {
+			bytes[i/8] |= 1 << (7 - i%8)
+		}
+		if last, ok := netip.AddrFromSlice(bytes); ok {
+			candidates = append(candidates, last.Next())
+		}
+	}
+	for _, addr := range candidates {
+		if addr.IsValid() && !set.Contains(addr) {
+			return append(values, addr.String())
+		}
+	}
+	return values
+}
+
+// Operator with swapped operands: 1 < [a] is [a] > 1
+func flip(op token.Token) token.Token {
+	switch op {
+	case token.LT:
+		return token.GT
+	case token.LTE:
+		return token.GTE
+	case token.GT:
+		return token.LT
+	case token.GTE:
+		return token.LTE
+	case token.SUBSETOF:
+		return token.SUPERSETOF
+	case token.NOTSUBSETOF:
+		return token.NOTSUPERSETOF
+	case token.SUPERSETOF:
+		return token.SUBSETOF
+	case token.NOTSUPERSETOF:
+		return token.NOTSUBSETOF
+	}
+	return op
+}
+
+// Set operators and equality compare whole arrays
+func setOperator(op token.Token) bool {
+	switch op {
+	case token.INTERSECTS, token.DISJOINT, token.SUBSETOF, token.NOTSUBSETOF, token.SUPERSETOF, token.NOTSUPERSETOF,
+		token.EQ, token.NEQ:
+		return true
+	}
+	return false
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
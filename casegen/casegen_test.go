@@ -0,0 +1,112 @@
+package casegen_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/casegen"
+	"github.com/thenam153/conditions-go/evaluator"
+	"github.com/thenam153/conditions-go/parser"
+)
+
+// Comparison leaves of expr, operands of logical operators
+func leaves(expr ast.Expr) []ast.Expr {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return leaves(e.Expr)
+	case *ast.BinaryExpr:
+		if e.OP.IsLogical() {
+			return append(leaves(e.LHS), leaves(e.RHS)...)
+		}
+	}
+	return []ast.Expr{expr}
+}
+
+func TestGenerateCoversLeaves(t *testing.T) {
+	tests := []struct {
+		expr   string
+		schema casegen.Schema
+		// Values a field must take, boundaries of ordering comparisons
+		want map[string][]any
+	}{
+		{expr: `[a] < 10`, want: map[string][]any{"a": {9.0, 10.0, 11.0}}},
+		{expr: `[a] <= 10`, want: map[string][]any{"a": {9.0, 10.0, 11.0}}},
+		{expr: `[a] > 10`, want: map[string][]any{"a": {9.0, 10.0, 11.0}}},
+		{expr: `[a] >= 10`, want: map[string][]any{"a": {9.0, 10.0, 11.0}}},
+		{expr: `10 < [a]`, want: map[string][]any{"a": {9.0, 10.0, 11.0}}},
+		{
+			expr:   `[a] >= 1.5 AND [b] < 3`,
+			schema: casegen.Schema{"a": {Step: 0.5}, "b": {Type: casegen.Integer}},
+			want:   map[string][]any{"a": {1.0, 1.5, 2.0}, "b": {2, 3, 4}},
+		},
+		{expr: `[a] == 1 OR [b] != "x"`},
+		{expr: `[name] == "bob" AND [ok]`},
+		{expr: `[s] < "m"`, want: map[string][]any{"s": {"", "m", "m_"}}},
+		{expr: `[s] >= "aé"`, want: map[string][]any{"s": {"a", "aé", "aé_"}}},
+		{
+			expr: `ANY x IN [items] : x.qty > 0`,
+			want: map[string][]any{"items": {[]any{}, []any{map[string]any{"qty": -1.0}}, []any{map[string]any{"qty": 1.0}}}},
+		},
+		{expr: `ALL x IN [xs] : x >= 2 AND [flag]`, want: map[string][]any{"xs": {[]any{}, []any{1.0}, []any{3.0}}}},
+		{
+			expr: `NONE x IN [items] : (x.qty > 0 AND x.sku == "a")`,
+			want: map[string][]any{"items": {[]any{map[string]any{"qty": -1.0, "sku": "a"}}, []any{map[string]any{"qty": -1.0, "sku": "a_"}}}},
+		},
+		{
+			expr: `ANY o IN [orders] : (ANY l IN o.lines : l.ok)`,
+			want: map[string][]any{"orders": {[]any{map[string]any{"lines": []any{map[string]any{"ok": false}}}}}},
+		},
+		{expr: `[s] CONTAINS "x" AND [s] NOT STARTS WITH "y"`},
+		{expr: `[s] LIKE "a%b" OR [s] =~ /^c[0-9]+$/`},
+		{expr: `[a] IN [1, 2, 3] AND [s] NOT IN ["x", "y"]`},
+		{expr: `"x" IN [tags]`},
+		{expr: `[a] BETWEEN 1 AND 10`, want: map[string][]any{"a": {0.0, 1.0, 10.0, 11.0}}},
+		{expr: `[a] IS NULL OR [b] IS NOT NULL`},
+		{expr: `[d] > 1h AND [t] < t"2026-01-01T00:00:00Z"`},
+		{expr: `[v] SATISFIES "^1.2"`},
+		{expr: `[ip] IN CIDR ["10.0.0.0/8"]`},
+		{expr: `[tags] INTERSECTS ["a", "b"]`},
+		{expr: `[a] < [b]`},
+		{
+			expr:   `[a] == 1`,
+			schema: casegen.Schema{"a": {Values: []any{5.0, 7.0}}, "c": {Values: []any{"x", "y"}}},
+			want:   map[string][]any{"a": {1.0, 2.0, 5.0, 7.0}, "c": {"x", "y"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := parser.NewParser(strings.NewReader(tt.expr)).Parse()
+			if err != nil {
+				t.Fatal(err)
+			}
+			cases, err := casegen.Generate(expr, tt.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, leaf := range leaves(expr) {
+				seen := map[bool]bool{}
+				for _, args := range cases {
+					if got, err := evaluator.Evaluate(leaf, args); err == nil {
+						seen[got] = true
+					}
+				}
+				if !seen[true] || !seen[false] {
+					t.Errorf("leaf %v: got outcomes %v over %v, want true and false", ast.String(leaf), seen, cases)
+				}
+			}
+			for field, values := range tt.want {
+				for _, want := range values {
+					found := false
+					for _, args := range cases {
+						found = found || reflect.DeepEqual(args[field], want)
+					}
+					if !found {
+						t.Errorf("field %v: no case with value %#v in %v", field, want, cases)
+					}
+				}
+			}
+		})
+	}
+}
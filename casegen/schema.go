@@ -0,0 +1,40 @@
+package casegen
+
+type Type int
+
+const (
+	// Unknown type is inferred from the literals a field is compared with
+	Unknown Type = iota
+	Number
+	Integer
+	String
+	Bool
+	SliceString
+	SliceNumber
+)
+
+// Schema describes the fields of args, it is optional for every field
+type Schema map[string]Field
+
+type Field struct {
+	Type Type
+	// Step used to build boundary values of numeric comparisons, default 1
+	Step float64
+	// Values are extra candidates, each of them is assigned to the field in
+	// some case. A field no comparison constrains defaults to the first one.
+	Values []any
+}
+
+func (s Schema) field(name string) Field {
+	if s == nil {
+		return Field{}
+	}
+	return s[name]
+}
+
+func (f Field) step() float64 {
+	if f.Step > 0 {
+		return f.Step
+	}
+	return 1
+}
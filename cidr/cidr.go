@@ -0,0 +1,142 @@
+// Package cidr matches IP addresses against sets of CIDR prefixes with a
+// binary prefix trie, lookups cost one step per address bit whatever the
+// number of prefixes.
+package cidr
+
+import (
+	"net/netip"
+	"strings"
+
+	lerrors "github.com/thenam153/conditions-go/errors"
+)
+
+// Set is an immutable set of IPv4 and IPv6 prefixes, safe for concurrent use
+type Set struct {
+	prefixes []netip.Prefix
+	v4       *node
+	v6       *node
+}
+
+type node struct {
+	child [2]*node
+	// A prefix ends at this node, every address below it is in the set
+	terminal bool
+}
+
+// NewSet returns the set of prefixes, host bits of the prefixes are ignored
+func NewSet(prefixes ...netip.Prefix) *Set {
+	s := &Set{v4: &node{}, v6: &node{}}
+	for _, p := range prefixes {
+		p = p.Masked()
+		if !p.IsValid() {
+			continue
+		}
+		s.prefixes = append(s.prefixes, p)
+		root := s.v6
+		if p.Addr().Is4() {
+			root = s.v4
+		}
+		insert(root, p)
+	}
+	return s
+}
+
+// Parse parses prefixes such as "10.0.0.0/8" or "2001:db8::/32", an address
+// without length is a single host prefix
+func Parse(prefixes ...string) (*Set, error) {
+	parsed := make([]netip.Prefix, 0, len(prefixes))
+	for _, s := range prefixes {
+		p, err := ParsePrefix(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, p)
+	}
+	return NewSet(parsed...), nil
+}
+
+// ParsePrefix parses one prefix of Parse
+func ParsePrefix(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, lerrors.NewWrap("Invalid CIDR prefix "+s, err)
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, lerrors.NewWrap("Invalid CIDR prefix "+s, err)
+	}
+	return p, nil
+}
+
+func insert(n *node, p netip.Prefix) {
+	bytes := p.Addr().As16()
+	offset := 0
+	if p.Addr().Is4() {
+		offset = 96
+	}
+	for i := 0; i < p.Bits(); i++ {
+		if n.terminal {
+			// A shorter prefix already holds p
+			return
+		}
+		b := bit(bytes, offset+i)
+		if n.child[b] == nil {
+			n.child[b] = &node{}
+		}
+		n = n.child[b]
+	}
+	n.terminal, n.child = true, [2]*node{}
+}
+
+func bit(bytes [16]byte, i int) int {
+	return int(bytes[i/8]>>(7-i%8)) & 1
+}
+
+// Contains reports whether addr is in a prefix of the set. An IPv4-mapped
+// IPv6 address is also matched against the IPv4 prefixes.
+func (s *Set) Contains(addr netip.Addr) bool {
+	addr = addr.WithZone("")
+	switch {
+	case addr.Is4():
+		return lookup(s.v4, addr)
+	case addr.Is4In6():
+		return lookup(s.v6, addr) || lookup(s.v4, addr.Unmap())
+	case addr.Is6():
+		return lookup(s.v6, addr)
+	}
+	return false
+}
+
+func lookup(n *node, addr netip.Addr) bool {
+	bytes := addr.As16()
+	offset := 0
+	if addr.Is4() {
+		offset = 96
+	}
+	for i := offset; i < 128; i++ {
+		if n.terminal {
+			return true
+		}
+		if n = n.child[bit(bytes, i)]; n == nil {
+			return false
+		}
+	}
+	return n.terminal
+}
+
+// Prefixes returns the prefixes of the set in insertion order
+func (s *Set) Prefixes() []netip.Prefix {
+	return s.prefixes
+}
+
+// Strings returns the prefixes of the set as strings
+func (s *Set) Strings() []string {
+	strs := make([]string, len(s.prefixes))
+	for i, p := range s.prefixes {
+		strs[i] = p.String()
+	}
+	return strs
+}
@@ -0,0 +1,146 @@
+package cidr_test
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/thenam153/conditions-go/cidr"
+)
+
+func TestContains(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []string
+		in       []string
+		out      []string
+	}{
+		{
+			name:     "overlapping longest first",
+			prefixes: []string{"10.1.2.0/24", "10.1.0.0/16", "10.0.0.0/8"},
+			in:       []string{"10.0.0.0", "10.1.2.3", "10.200.0.1", "10.255.255.255"},
+			out:      []string{"9.255.255.255", "11.0.0.0"},
+		},
+		{
+			name:     "overlapping shortest first",
+			prefixes: []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24"},
+			in:       []string{"10.0.0.0", "10.1.2.3", "10.200.0.1", "10.255.255.255"},
+			out:      []string{"9.255.255.255", "11.0.0.0"},
+		},
+		{
+			name:     "siblings",
+			prefixes: []string{"192.168.1.0/25", "192.168.1.128/25"},
+			in:       []string{"192.168.1.0", "192.168.1.127", "192.168.1.128", "192.168.1.255"},
+			out:      []string{"192.168.0.255", "192.168.2.0"},
+		},
+		{
+			name:     "host bits ignored",
+			prefixes: []string{"172.16.5.4/12"},
+			in:       []string{"172.16.0.0", "172.31.255.255"},
+			out:      []string{"172.32.0.0"},
+		},
+		{
+			name:     "IPv4 /0",
+			prefixes: []string{"0.0.0.0/0"},
+			in:       []string{"0.0.0.0", "255.255.255.255", "::ffff:1.2.3.4"},
+			out:      []string{"::1", "2001:db8::1"},
+		},
+		{
+			name:     "IPv6 /0",
+			prefixes: []string{"::/0"},
+			in:       []string{"::", "2001:db8::1", "::ffff:1.2.3.4"},
+			out:      []string{"1.2.3.4"},
+		},
+		{
+			name:     "/32 and bare IPv4",
+			prefixes: []string{"1.2.3.4/32", "5.6.7.8"},
+			in:       []string{"1.2.3.4", "5.6.7.8"},
+			out:      []string{"1.2.3.5", "5.6.7.9", "1.2.3.3"},
+		},
+		{
+			name:     "/128 and bare IPv6",
+			prefixes: []string{"2001:db8::1/128", "2001:db8::ff"},
+			in:       []string{"2001:db8::1", "2001:db8::ff"},
+			out:      []string{"2001:db8::2", "2001:db8::"},
+		},
+		{
+			name:     "IPv6",
+			prefixes: []string{"2001:db8::/32", "fe80::/10"},
+			in:       []string{"2001:db8:ffff::1", "fe80::1", "febf::1"},
+			out:      []string{"2001:db9::", "fec0::1"},
+		},
+		{
+			name:     "zoned addresses",
+			prefixes: []string{"fe80::/10"},
+			in:       []string{"fe80::1%eth0", "fe80::abcd%1"},
+			out:      []string{"fec0::1%eth0"},
+		},
+		{
+			name:     "IPv4-mapped IPv6 matches IPv4 prefixes",
+			prefixes: []string{"10.0.0.0/8"},
+			in:       []string{"::ffff:10.1.2.3", "::ffff:a00:1"},
+			out:      []string{"::ffff:11.0.0.1", "::a00:1", "10::1"},
+		},
+		{
+			name:     "IPv4-mapped IPv6 prefix",
+			prefixes: []string{"::ffff:10.0.0.0/104"},
+			in:       []string{"::ffff:10.1.2.3"},
+			out:      []string{"10.1.2.3"},
+		},
+		{
+			name: "empty",
+			out:  []string{"0.0.0.0", "::"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := cidr.Parse(tt.prefixes...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.in {
+				if !set.Contains(netip.MustParseAddr(s)) {
+					t.Errorf("%v not in %v", s, tt.prefixes)
+				}
+			}
+			for _, s := range tt.out {
+				if set.Contains(netip.MustParseAddr(s)) {
+					t.Errorf("%v in %v", s, tt.prefixes)
+				}
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"10.0.0.0/33", "::/129", "10.0.0", "10.0.0.0/-1", "", "not an ip"} {
+		if _, err := cidr.Parse(s); err == nil {
+			t.Errorf("Parse(%q): no error", s)
+		}
+	}
+	p, err := cidr.ParsePrefix("10.1.2.3")
+	if err != nil || p != netip.MustParsePrefix("10.1.2.3/32") {
+		t.Errorf("ParsePrefix of an address = %v, %v, want a host prefix", p, err)
+	}
+}
+
+func TestPrefixes(t *testing.T) {
+	set, err := cidr.Parse("10.1.2.3/8", "2001:db8::/32", "10.1.0.0/16", "1.2.3.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Insertion order, masked, prefixes held by a shorter one are kept
+	want := []string{"10.0.0.0/8", "2001:db8::/32", "10.1.0.0/16", "1.2.3.4/32"}
+	if got := set.Strings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Strings() = %v, want %v", got, want)
+	}
+	prefixes := set.Prefixes()
+	if len(prefixes) != len(want) {
+		t.Fatalf("Prefixes() = %v, want %v", prefixes, want)
+	}
+	for i, p := range prefixes {
+		if p != netip.MustParsePrefix(want[i]) {
+			t.Errorf("Prefixes()[%d] = %v, want %v", i, p, want[i])
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Command condtest runs the examples of declarative rule test-spec files
+//
+//	condtest [-v] [-junit report.xml] [-cover] [-coverhtml coverage.html] spec.json...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/thenam153/conditions-go/condtest"
+	"github.com/thenam153/conditions-go/evaluator"
+)
+
+func main() {
+	var (
+		verbose   = flag.Bool("v", false, "print passed cases")
+		junit     = flag.String("junit", "", "write JUnit XML report to `file`")
+		cover     = flag.Bool("cover", false, "print rule coverage")
+		coverHTML = flag.String("coverhtml", "", "write HTML coverage report to `file`")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: condtest [flags] spec.json...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	suites := make([]*condtest.Suite, 0, flag.NArg())
+	for _, path := range flag.Args() {
+		suite, err := condtest.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "condtest: %v: %v\n", path, err)
+			os.Exit(2)
+		}
+		suites = append(suites, suite)
+	}
+	var cov *evaluator.Coverage
+	if *cover || *coverHTML != "" {
+		cov = evaluator.NewCoverage()
+	}
+	report := condtest.RunWithCoverage(cov, suites...)
+	if err := report.WriteText(os.Stdout, *verbose); err != nil {
+		fmt.Fprintf(os.Stderr, "condtest: %v\n", err)
+		os.Exit(2)
+	}
+	if *junit != "" {
+		if err := writeFile(*junit, report.WriteJUnit); err != nil {
+			fmt.Fprintf(os.Stderr, "condtest: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if *cover {
+		if err := cov.WriteText(os.Stdout, report.Sources...); err != nil {
+			fmt.Fprintf(os.Stderr, "condtest: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if *coverHTML != "" {
+		if err := writeFile(*coverHTML, func(w io.Writer) error {
+			return cov.WriteHTML(w, report.Sources...)
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "condtest: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if report.Failed() > 0 {
+		os.Exit(1)
+	}
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
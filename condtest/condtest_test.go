@@ -0,0 +1,100 @@
+package condtest_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thenam153/conditions-go/condtest"
+)
+
+func TestLoadRejectsIncompleteRules(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{"empty expression", `{"rules": [{"name": "r", "expression": " ", "cases": [{"args": {}, "expect": true}]}]}`, "empty expression"},
+		{"no expression", `{"rules": [{"name": "r", "cases": [{"args": {}, "expect": true}]}]}`, "empty expression"},
+		{"no cases", `{"rules": [{"name": "r", "expression": "[a] == 1"}]}`, "no cases"},
+		{"empty cases", `{"rules": [{"name": "r", "expression": "[a] == 1", "cases": []}]}`, "no cases"},
+		{"expect and error", `{"rules": [{"name": "r", "expression": "[a] == 1", "cases": [{"args": {}, "expect": true, "error": ""}]}]}`, "either expect or error"},
+		{"no expectation", `{"rules": [{"name": "r", "expression": "[a] == 1", "cases": [{"name": "c", "args": {}}]}]}`, "Case c of rule r"},
+		{"undeclared variable", `{"rules": [{"name": "r", "expression": "[a] == 1", "cases": [{"args": {}, "variables": {"v": 1}, "expect": true}]}]}`, "undeclared variable v"},
+		{"valid", `{"rules": [{"name": "r", "expression": "[a] == 1", "cases": [{"args": {"a": 1}, "expect": true}]}]}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "spec.json")
+			if err := os.WriteFile(path, []byte(tt.spec), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := condtest.Load(path)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRunReportsParseErrorOnce(t *testing.T) {
+	expect := true
+	suites := []*condtest.Suite{{
+		Name: "s",
+		Rules: []condtest.Rule{
+			{Name: "broken", Expression: "[a] ==", Cases: []condtest.Case{{Expect: &expect}, {Expect: &expect}}},
+			{Name: "broken without cases", Expression: "[a] =="},
+		},
+	}}
+	report := condtest.Run(suites...)
+	if len(report.Results) != 2 || report.Failed() != 2 {
+		t.Fatalf("got %d results, %d failed, want one failed result per rule: %+v", len(report.Results), report.Failed(), report.Results)
+	}
+	for _, res := range report.Results {
+		if res.Case != "parse" || !strings.Contains(res.Message, "Cannot parse expression") {
+			t.Fatalf("unexpected result %+v", res)
+		}
+	}
+	if len(report.Sources) != 0 {
+		t.Fatalf("broken rules must not be coverage sources: %+v", report.Sources)
+	}
+}
+
+func TestRunParserOptions(t *testing.T) {
+	spec := `{"rules": [
+		{
+			"name": "variables",
+			"expression": "$jq(.sku == $sku)",
+			"variables": {"sku": "A1"},
+			"cases": [
+				{"name": "default", "args": {"sku": "A1"}, "expect": true},
+				{"name": "override", "args": {"sku": "A1"}, "variables": {"sku": "B2"}, "expect": false}
+			]
+		},
+		{"name": "strict", "expression": "[a] IN [1, \"1\"]", "strictArrays": true, "cases": [{"args": {"a": 1}, "expect": true}]},
+		{"name": "lax", "expression": "[a] IN [1, \"1\"]", "cases": [{"args": {"a": 1}, "expect": true}]}
+	]}`
+	path := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	suite, err := condtest.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := condtest.Run(suite)
+	want := map[string]bool{"variables/default": true, "variables/override": true, "strict/parse": false, "lax/case 0": true}
+	if len(report.Results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(report.Results), len(want), report.Results)
+	}
+	for _, res := range report.Results {
+		passed, ok := want[res.Rule+"/"+res.Case]
+		if !ok || res.Passed != passed {
+			t.Errorf("unexpected result %+v", res)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package condtest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteText writes failed cases with their evaluation trace and a summary line
+func (r *Report) WriteText(w io.Writer, verbose bool) error {
+	for _, res := range r.Results {
+		if res.Passed {
+			if verbose {
+				if _, err := fmt.Fprintf(w, "PASS %v/%v/%v\n", res.Suite, res.Rule, res.Case); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "FAIL %v/%v/%v: %v\n", res.Suite, res.Rule, res.Case, res.Message); err != nil {
+			return err
+		}
+		if res.Trace != "" {
+			if _, err := fmt.Fprintf(w, "%v", indent(res.Trace, "    ")); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d cases, %d failed\n", len(r.Results), r.Failed())
+	return err
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",cdata"`
+}
+
+// WriteJUnit writes the report as JUnit XML, one testsuite per spec suite
+func (r *Report) WriteJUnit(w io.Writer) error {
+	doc := junitSuites{Tests: len(r.Results), Failures: r.Failed()}
+	index := map[string]int{}
+	seconds := map[string]float64{}
+	for _, res := range r.Results {
+		i, ok := index[res.Suite]
+		if !ok {
+			i = len(doc.Suites)
+			index[res.Suite] = i
+			doc.Suites = append(doc.Suites, junitSuite{Name: res.Suite})
+		}
+		suite := &doc.Suites[i]
+		tc := junitCase{
+			Name:      res.Case,
+			Classname: res.Suite + "." + res.Rule,
+			Time:      fmt.Sprintf("%.6f", res.Duration.Seconds()),
+		}
+		if !res.Passed {
+			tc.Failure = &junitFailure{Message: res.Message, Body: res.Trace}
+			suite.Failures++
+		}
+		suite.Tests++
+		seconds[res.Suite] += res.Duration.Seconds()
+		suite.Cases = append(suite.Cases, tc)
+	}
+	for i := range doc.Suites {
+		doc.Suites[i].Time = fmt.Sprintf("%.6f", seconds[doc.Suites[i].Name])
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func indent(s, prefix string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,153 @@
+package condtest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thenam153/conditions-go/ast"
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/evaluator"
+	"github.com/thenam153/conditions-go/parser"
+)
+
+// Result of a single case, Trace is filled for failed cases only
+type Result struct {
+	Suite    string
+	Rule     string
+	Case     string
+	Passed   bool
+	Message  string
+	Trace    string
+	Duration time.Duration
+}
+
+type Report struct {
+	Results []Result
+	// Parsed rules, used to write coverage reports
+	Sources []evaluator.CoverageSource
+}
+
+func (r *Report) Failed() int {
+	n := 0
+	for _, res := range r.Results {
+		if !res.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// Run parses every rule of the suites and evaluates it against its cases
+func Run(suites ...*Suite) *Report {
+	return RunWithCoverage(nil, suites...)
+}
+
+// RunWithCoverage runs the suites and records coverage of every rule into cov
+func RunWithCoverage(cov *evaluator.Coverage, suites ...*Suite) *Report {
+	report := &Report{}
+	for _, suite := range suites {
+		for _, rule := range suite.Rules {
+			expr, results := runRule(suite.Name, rule, cov)
+			report.Results = append(report.Results, results...)
+			if expr != nil {
+				report.Sources = append(report.Sources, evaluator.CoverageSource{
+					Name:   suite.Name + "/" + rule.Name,
+					Source: rule.Expression,
+					Expr:   expr,
+				})
+			}
+		}
+	}
+	return report
+}
+
+// A rule whose expression cannot be parsed fails once, as case "parse",
+// rather than once per case
+func runRule(suite string, rule Rule, cov *evaluator.Coverage) (ast.Expr, []Result) {
+	var opts []parser.Option
+	if len(rule.Variables) > 0 {
+		names := make([]string, 0, len(rule.Variables))
+		for name := range rule.Variables {
+			names = append(names, name)
+		}
+		opts = append(opts, parser.WithJQVariables(names...))
+	}
+	if rule.StrictArrays {
+		opts = append(opts, parser.WithStrictArrays())
+	}
+	expr, err := parser.NewParser(strings.NewReader(rule.Expression), opts...).Parse()
+	if err != nil {
+		return nil, []Result{{
+			Suite:   suite,
+			Rule:    rule.Name,
+			Case:    "parse",
+			Message: lerrors.NewWrap("Cannot parse expression", err).Error(),
+		}}
+	}
+	results := make([]Result, 0, len(rule.Cases))
+	for i, c := range rule.Cases {
+		res := Result{Suite: suite, Rule: rule.Name, Case: c.Name}
+		if res.Case == "" {
+			res.Case = fmt.Sprintf("case %d", i)
+		}
+		start := time.Now()
+		runCase(expr, c, variables(rule, c), cov, &res)
+		res.Duration = time.Since(start)
+		results = append(results, res)
+	}
+	return expr, results
+}
+
+// Variables of the rule overridden by those of the case
+func variables(rule Rule, c Case) map[string]any {
+	if len(c.Variables) == 0 {
+		return rule.Variables
+	}
+	vars := make(map[string]any, len(rule.Variables))
+	for name, value := range rule.Variables {
+		vars[name] = value
+	}
+	for name, value := range c.Variables {
+		vars[name] = value
+	}
+	return vars
+}
+
+func runCase(expr ast.Expr, c Case, vars map[string]any, cov *evaluator.Coverage, res *Result) {
+	trace := &evaluator.Trace{}
+	defer func() {
+		if r := recover(); r != nil {
+			res.Passed = false
+			res.Message = fmt.Sprintf("panic: %v", r)
+			res.Trace = trace.String()
+		}
+	}()
+	opts := []evaluator.Option{evaluator.WithTrace(trace)}
+	if len(vars) > 0 {
+		opts = append(opts, evaluator.WithJQVariables(vars))
+	}
+	if cov != nil {
+		opts = append(opts, evaluator.WithCoverage(cov))
+	}
+	got, err := evaluator.Evaluate(expr, c.Args, opts...)
+	switch {
+	case c.Error != nil && err == nil:
+		res.Message = fmt.Sprintf("expected error, got %v", got)
+	case c.Error != nil && !strings.Contains(err.Error(), *c.Error):
+		res.Message = fmt.Sprintf("expected error containing %q, got: %v", *c.Error, err)
+	case c.Error != nil:
+		res.Passed = true
+	case err != nil:
+		res.Message = fmt.Sprintf("unexpected error: %v", err)
+	case c.Expect == nil:
+		res.Message = "case must set either expect or error"
+	case got != *c.Expect:
+		res.Message = fmt.Sprintf("expected %v, got %v", *c.Expect, got)
+	default:
+		res.Passed = true
+	}
+	if !res.Passed {
+		res.Trace = trace.String()
+	}
+}
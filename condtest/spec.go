@@ -0,0 +1,121 @@
+package condtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	lerrors "github.com/thenam153/conditions-go/errors"
+)
+
+// Suite is a test-spec file, every rule ships with its own examples
+//
+//	{
+//	  "rules": [
+//	    {
+//	      "name": "adult",
+//	      "expression": "[age] >= 18",
+//	      "cases": [
+//	        {"name": "old enough", "args": {"age": 20}, "expect": true},
+//	        {"name": "missing age", "args": {}, "error": "Cannot get args"}
+//	      ]
+//	    },
+//	    {
+//	      "name": "listed",
+//	      "expression": "$jq(.sku == $sku)",
+//	      "variables": {"sku": "A1"},
+//	      "strictArrays": true,
+//	      "cases": [
+//	        {"args": {"sku": "A1"}, "expect": true},
+//	        {"args": {"sku": "A1"}, "variables": {"sku": "B2"}, "expect": false}
+//	      ]
+//	    }
+//	  ]
+//	}
+type Suite struct {
+	Name  string `json:"name,omitempty"`
+	Rules []Rule `json:"rules"`
+}
+
+// Rule is an expression given inline or through a rule file, File is
+// relative to the spec file. Variables declares the JQ variables of the
+// expression with their default values, StrictArrays parses it with
+// parser.WithStrictArrays.
+type Rule struct {
+	Name         string         `json:"name"`
+	Expression   string         `json:"expression,omitempty"`
+	File         string         `json:"file,omitempty"`
+	Variables    map[string]any `json:"variables,omitempty"`
+	StrictArrays bool           `json:"strictArrays,omitempty"`
+	Cases        []Case         `json:"cases"`
+}
+
+// Case expects either a boolean result or an evaluation error. An empty
+// Error matches any error, otherwise the error message must contain it.
+// Variables overrides values of the JQ variables declared by the rule.
+type Case struct {
+	Name      string         `json:"name,omitempty"`
+	Args      map[string]any `json:"args"`
+	Variables map[string]any `json:"variables,omitempty"`
+	Expect    *bool          `json:"expect,omitempty"`
+	Error     *string        `json:"error,omitempty"`
+}
+
+// Load reads a test-spec file, rule files referenced by the spec are
+// resolved and read into Rule.Expression.
+func Load(path string) (*Suite, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, lerrors.NewWrap("Cannot read spec file", err)
+	}
+	suite := &Suite{}
+	if err = json.Unmarshal(bytes, suite); err != nil {
+		return nil, lerrors.NewWrap("Cannot unmarshal spec file", err)
+	}
+	if suite.Name == "" {
+		suite.Name = filepath.Base(path)
+	}
+	for i := range suite.Rules {
+		rule := &suite.Rules[i]
+		if rule.Name == "" {
+			return nil, lerrors.Newf("Rule %d of %v has no name", i, path)
+		}
+		if rule.File != "" {
+			if rule.Expression != "" {
+				return nil, lerrors.Newf("Rule %v must set either expression or file", rule.Name)
+			}
+			file := rule.File
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(filepath.Dir(path), file)
+			}
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return nil, lerrors.NewWrap("Cannot read rule file", err)
+			}
+			rule.Expression = string(src)
+		}
+		if strings.TrimSpace(rule.Expression) == "" {
+			return nil, lerrors.Newf("Rule %v has an empty expression", rule.Name)
+		}
+		if len(rule.Cases) == 0 {
+			return nil, lerrors.Newf("Rule %v has no cases", rule.Name)
+		}
+		for j, c := range rule.Cases {
+			name := c.Name
+			if name == "" {
+				name = fmt.Sprintf("case %d", j)
+			}
+			if (c.Expect == nil) == (c.Error == nil) {
+				return nil, lerrors.Newf("Case %v of rule %v must set either expect or error", name, rule.Name)
+			}
+			for v := range c.Variables {
+				if _, ok := rule.Variables[v]; !ok {
+					return nil, lerrors.Newf("Case %v of rule %v sets undeclared variable %v", name, rule.Name, v)
+				}
+			}
+		}
+	}
+	return suite, nil
+}
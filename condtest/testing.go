@@ -0,0 +1,31 @@
+package condtest
+
+import (
+	"testing"
+)
+
+// RunFiles loads test-spec files and runs every case as a subtest of t
+//
+//	func TestRules(t *testing.T) {
+//		condtest.RunFiles(t, "testdata/rules.json")
+//	}
+func RunFiles(t *testing.T, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		suite, err := Load(path)
+		if err != nil {
+			t.Fatalf("%v: %v", path, err)
+		}
+		for _, rule := range suite.Rules {
+			_, results := runRule(suite.Name, rule, nil)
+			for _, res := range results {
+				res := res
+				t.Run(res.Rule+"/"+res.Case, func(t *testing.T) {
+					if !res.Passed {
+						t.Errorf("%v\n%v", res.Message, res.Trace)
+					}
+				})
+			}
+		}
+	}
+}
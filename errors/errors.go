@@ -5,19 +5,85 @@ import (
 	"fmt"
 )
 
+var (
+	// ErrUnknownVariable is returned when an argument referenced by the expression is missing
+	ErrUnknownVariable = errors.New("unknown variable")
+	// ErrInvalidRegex is returned when a regex operand cannot be compiled
+	ErrInvalidRegex = errors.New("invalid regex")
+	// ErrJQ is returned when a JQ query cannot be parsed, run or its result used
+	ErrJQ = errors.New("jq error")
+	// ErrUnsupportedValue is returned when an argument cannot be converted to a value
+	ErrUnsupportedValue = errors.New("unsupported value")
+	// ErrLimitExceeded is returned when an evaluation exceeds a configured limit
+	ErrLimitExceeded = errors.New("limit exceeded")
+)
+
 type Error struct {
 	ferr error
 	serr error
 }
 
+// Unwrap returns the wrapped cause
 func (e *Error) Unwrap() error {
 	return e.serr
 }
 
+// Is matches target against the message error too, e.g. a sentinel it wraps
+// with %w, the cause is matched through Unwrap
+func (e *Error) Is(target error) bool {
+	return errors.Is(e.ferr, target)
+}
+
+// As finds target in the message error too, the cause is searched through
+// Unwrap
+func (e *Error) As(target any) bool {
+	return errors.As(e.ferr, target)
+}
+
 func (e *Error) Error() string {
 	return fmt.Sprintf("%v, %v", e.ferr, e.serr)
 }
 
+// ErrTypeMismatch is returned when an operator is applied to operands of
+// unsupported types. Compared with errors.Is, empty fields of the target
+// match any value.
+type ErrTypeMismatch struct {
+	Op    string
+	Left  string
+	Right string
+}
+
+func (e ErrTypeMismatch) Error() string {
+	return fmt.Sprintf("type mismatch: cannot apply %v to %v and %v", e.Op, e.Left, e.Right)
+}
+
+func (e ErrTypeMismatch) Is(target error) bool {
+	t, ok := target.(ErrTypeMismatch)
+	if !ok {
+		return false
+	}
+	return (t.Op == "" || t.Op == e.Op) &&
+		(t.Left == "" || t.Left == e.Left) &&
+		(t.Right == "" || t.Right == e.Right)
+}
+
+// ParseError is returned by the parser, the position is the one of the
+// token where parsing stopped.
+type ParseError struct {
+	Offset int
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse error at %d:%d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
 func Wrap(ferr, serr error) error {
 	return &Error{ferr, serr}
 }
@@ -33,3 +99,16 @@ func Newf(f string, args ...any) error {
 func NewWrap(msg string, err error) error {
 	return &Error{errors.New(msg), err}
 }
+
+// Join returns an error wrapping all non-nil errs, nil if there is none
+func Join(errs ...error) error {
+	return errors.Join(errs...)
+}
+
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
@@ -0,0 +1,37 @@
+package errors_test
+
+import (
+	"errors"
+	"testing"
+
+	lerrors "github.com/thenam153/conditions-go/errors"
+)
+
+func TestErrorChain(t *testing.T) {
+	cause := lerrors.ErrTypeMismatch{Op: "==", Left: "number", Right: "string"}
+	err := lerrors.Wrap(lerrors.Newf("Cannot run query: %w", lerrors.ErrJQ), lerrors.NewWrap("Cannot evaluate", cause))
+
+	// The cause is what the standard library unwraps to
+	inner := errors.Unwrap(err)
+	if inner == nil || errors.Unwrap(inner) != cause {
+		t.Fatalf("got chain %v, %v, want the cause last", inner, errors.Unwrap(inner))
+	}
+	if !errors.Is(err, lerrors.ErrJQ) {
+		t.Fatal("sentinel of the message error not matched")
+	}
+	if !errors.Is(err, lerrors.ErrTypeMismatch{Op: "=="}) {
+		t.Fatal("cause not matched")
+	}
+	if errors.Is(err, lerrors.ErrUnknownVariable) {
+		t.Fatal("unrelated sentinel matched")
+	}
+	var mismatch lerrors.ErrTypeMismatch
+	if !errors.As(err, &mismatch) || mismatch != cause {
+		t.Fatalf("got %v, want %v", mismatch, cause)
+	}
+	var parseErr *lerrors.ParseError
+	wrapped := lerrors.NewWrap("Cannot load rule", &lerrors.ParseError{Line: 1, Column: 2, Err: lerrors.ErrJQ})
+	if !errors.As(wrapped, &parseErr) || parseErr.Column != 2 || !errors.Is(wrapped, lerrors.ErrJQ) {
+		t.Fatalf("parse error not found in %v", wrapped)
+	}
+}
@@ -1,10 +1,13 @@
 package evaluator
 
 import (
+	"reflect"
 	"regexp"
+	"strings"
 
 	"github.com/thenam153/conditions-go/ast"
 	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/semver"
 	"github.com/thenam153/conditions-go/token"
 )
 
@@ -38,6 +41,18 @@ func applyOperator(op token.Token, lhs, rhs ast.Expr) (*ast.BooleanLiteral, erro
 		return applyEREG(lhs, rhs)
 	case token.NEREG:
 		return applyNEREG(lhs, rhs)
+	case token.CONTAINS, token.NOTCONTAINS, token.ICONTAINS, token.NOTICONTAINS,
+		token.STARTSWITH, token.NOTSTARTSWITH, token.ENDSWITH, token.NOTENDSWITH,
+		token.LIKE, token.NOTLIKE, token.ILIKE, token.NOTILIKE:
+		return applyString(op, lhs, rhs)
+	case token.SATISFIES, token.NOTSATISFIES:
+		return applySatisfies(op, lhs, rhs)
+	case token.INCIDR, token.NOTINCIDR:
+		return applyCIDR(op, lhs, rhs)
+	case token.INTERSECTS, token.DISJOINT, token.SUBSETOF, token.NOTSUBSETOF, token.SUPERSETOF, token.NOTSUPERSETOF:
+		return applySet(op, lhs, rhs)
+	case token.BETWEEN, token.NOTBETWEEN:
+		return applyBetween(op, lhs, rhs, strings.Compare)
 	default:
 		return nil, lerrors.Newf("Not implemented operator, Op: %v", op.String())
 	}
@@ -49,10 +64,10 @@ func applyAND(l, r ast.Expr) (*ast.BooleanLiteral, error) {
 		err    error
 	)
 	if lv, err = getBool(l); err != nil {
-		return nil, err
+		return nil, mismatch(token.AND, l, r)
 	}
 	if rv, err = getBool(r); err != nil {
-		return nil, err
+		return nil, mismatch(token.AND, l, r)
 	}
 	return &ast.BooleanLiteral{Value: lv && rv}, nil
 }
@@ -63,10 +78,10 @@ func applyNAND(l, r ast.Expr) (*ast.BooleanLiteral, error) {
 		err    error
 	)
 	if lv, err = getBool(l); err != nil {
-		return nil, err
+		return nil, mismatch(token.NAND, l, r)
 	}
 	if rv, err = getBool(r); err != nil {
-		return nil, err
+		return nil, mismatch(token.NAND, l, r)
 	}
 	return &ast.BooleanLiteral{Value: !(lv && rv)}, nil
 }
@@ -77,10 +92,10 @@ func applyOR(l, r ast.Expr) (*ast.BooleanLiteral, error) {
 		err    error
 	)
 	if lv, err = getBool(l); err != nil {
-		return nil, err
+		return nil, mismatch(token.OR, l, r)
 	}
 	if rv, err = getBool(r); err != nil {
-		return nil, err
+		return nil, mismatch(token.OR, l, r)
 	}
 	return &ast.BooleanLiteral{Value: lv || rv}, nil
 }
@@ -91,179 +106,386 @@ func applyXOR(l, r ast.Expr) (*ast.BooleanLiteral, error) {
 		err    error
 	)
 	if lv, err = getBool(l); err != nil {
-		return nil, err
+		return nil, mismatch(token.XOR, l, r)
 	}
 	if rv, err = getBool(r); err != nil {
-		return nil, err
+		return nil, mismatch(token.XOR, l, r)
 	}
 	return &ast.BooleanLiteral{Value: (lv || rv) && (!lv || !rv)}, nil
 }
 
 func applyEQ(l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	equal, err := compareEQ(token.EQ, l, r)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.BooleanLiteral{Value: equal}, nil
+}
+
+func applyNEQ(l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	equal, err := compareEQ(token.NEQ, l, r)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.BooleanLiteral{Value: !equal}, nil
+}
+
+func compareEQ(op token.Token, l, r ast.Expr) (bool, error) {
 	var (
 		lvs, rvs string
 		lvn, rvn float64
 		lvb, rvb bool
 		err      error
 	)
+	l, r = coerce(l, r)
+	switch lv := l.(type) {
+	case *ast.TimeLiteral:
+		rv, ok := r.(*ast.TimeLiteral)
+		if !ok {
+			return false, mismatch(op, l, r)
+		}
+		return lv.Value.Equal(rv.Value), nil
+	case *ast.DurationLiteral:
+		rv, ok := r.(*ast.DurationLiteral)
+		if !ok {
+			return false, mismatch(op, l, r)
+		}
+		return lv.Value == rv.Value, nil
+	case *ast.VersionLiteral:
+		rv, ok := r.(*ast.VersionLiteral)
+		if !ok {
+			return false, mismatch(op, l, r)
+		}
+		return lv.Value.Compare(rv.Value) == 0, nil
+	case *ast.IPLiteral:
+		rv, ok := r.(*ast.IPLiteral)
+		if !ok {
+			return false, mismatch(op, l, r)
+		}
+		return lv.Value == rv.Value, nil
+	}
 	if lvs, err = getString(l); err == nil {
 		if rvs, err = getString(r); err != nil {
-			return nil, lerrors.New("Cannot compare string with non-string")
+			return false, mismatch(op, l, r)
 		}
-		return &ast.BooleanLiteral{Value: lvs == rvs}, nil
+		return lvs == rvs, nil
 	}
 	if lvn, err = getNumber(l); err == nil {
 		if rvn, err = getNumber(r); err != nil {
-			return nil, lerrors.New("Cannot compare number with non-number")
+			return false, mismatch(op, l, r)
 		}
-		return &ast.BooleanLiteral{Value: lvn == rvn}, nil
+		return lvn == rvn, nil
 	}
 	if lvb, err = getBool(l); err == nil {
 		if rvb, err = getBool(r); err != nil {
-			return nil, lerrors.New("Cannot compare boolean with non-boolean")
+			return false, mismatch(op, l, r)
 		}
-		return &ast.BooleanLiteral{Value: lvb == rvb}, nil
+		return lvb == rvb, nil
 	}
-	return &ast.BooleanLiteral{Value: false}, nil
-}
-
-func applyNEQ(l, r ast.Expr) (*ast.BooleanLiteral, error) {
-	result, err := applyEQ(l, r)
-	if err != nil {
-		return result, err
+	if lvo, ok := l.(*ast.ObjectLiteral); ok {
+		rvo, ok := r.(*ast.ObjectLiteral)
+		if !ok {
+			return false, mismatch(op, l, r)
+		}
+		return jsonEqual(lvo.Value, rvo.Value), nil
+	}
+	// Arrays are equal as sets
+	if equal, ok, err := setEqual(op, l, r); ok {
+		return equal, err
 	}
-	result.Value = !result.Value
-	return result, nil
+	return false, nil
 }
 
 func applyEREG(l, r ast.Expr) (*ast.BooleanLiteral, error) {
-	var (
-		lv, rv string
-		err    error
-		match  bool
-	)
-	lv, err = getString(l)
+	match, err := matchRegex(token.EREG, l, r)
 	if err != nil {
 		return nil, err
 	}
-	rv, err = getString(r)
-	if err != nil {
-		return nil, err
-	}
-	match, err = regexp.MatchString(rv, lv)
-	return &ast.BooleanLiteral{Value: match}, err
+	return &ast.BooleanLiteral{Value: match}, nil
 }
 
 func applyNEREG(l, r ast.Expr) (*ast.BooleanLiteral, error) {
-	result, err := applyEREG(l, r)
+	match, err := matchRegex(token.NEREG, l, r)
 	if err != nil {
 		return nil, err
 	}
-	result.Value = !result.Value
-	return result, nil
+	return &ast.BooleanLiteral{Value: !match}, nil
 }
 
-func applyGT(l, r ast.Expr) (*ast.BooleanLiteral, error) {
+func matchRegex(op token.Token, l, r ast.Expr) (bool, error) {
 	var (
-		lv, rv float64
+		lv, rv string
 		err    error
+		match  bool
 	)
-	if lv, err = getNumber(l); err != nil {
-		return nil, err
+	if lv, err = getString(l); err != nil {
+		return false, mismatch(op, l, r)
 	}
-	if rv, err = getNumber(r); err != nil {
-		return nil, err
+	if rv, err = getString(r); err != nil {
+		return false, mismatch(op, l, r)
 	}
-	return &ast.BooleanLiteral{Value: lv > rv}, nil
+	if match, err = regexp.MatchString(rv, lv); err != nil {
+		return false, lerrors.Wrap(lerrors.ErrInvalidRegex, err)
+	}
+	return match, nil
+}
+
+func applyGT(l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	return applyOrder(token.GT, l, r, strings.Compare)
 }
 
 func applyGTE(l, r ast.Expr) (*ast.BooleanLiteral, error) {
-	var (
-		lv, rv float64
-		err    error
-	)
-	if lv, err = getNumber(l); err != nil {
+	return applyOrder(token.GTE, l, r, strings.Compare)
+}
+
+func applyLT(l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	return applyOrder(token.LT, l, r, strings.Compare)
+}
+
+func applyLTE(l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	return applyOrder(token.LTE, l, r, strings.Compare)
+}
+
+func applyOrder(op token.Token, l, r ast.Expr, compareStrings func(a, b string) int) (*ast.BooleanLiteral, error) {
+	c, err := compare(op, l, r, compareStrings)
+	if err != nil {
 		return nil, err
 	}
-	if rv, err = getNumber(r); err != nil {
-		return nil, err
+	if c == unordered {
+		return &ast.BooleanLiteral{Value: false}, nil
+	}
+	switch op {
+	case token.GT:
+		return &ast.BooleanLiteral{Value: c > 0}, nil
+	case token.GTE:
+		return &ast.BooleanLiteral{Value: c >= 0}, nil
+	case token.LT:
+		return &ast.BooleanLiteral{Value: c < 0}, nil
+	default:
+		return &ast.BooleanLiteral{Value: c <= 0}, nil
 	}
-	return &ast.BooleanLiteral{Value: lv >= rv}, nil
 }
 
-func applyLT(l, r ast.Expr) (*ast.BooleanLiteral, error) {
-	var (
-		lv, rv float64
-		err    error
-	)
-	if lv, err = getNumber(l); err != nil {
+// Apply BETWEEN and NOT BETWEEN, r is a range of evaluated bounds ordered
+// like the operands of <
+func applyBetween(op token.Token, l, r ast.Expr, compareStrings func(a, b string) int) (*ast.BooleanLiteral, error) {
+	rng, ok := r.(*ast.RangeExpr)
+	if !ok {
+		return nil, mismatch(op, l, r)
+	}
+	low, err := compare(op, l, rng.Low, compareStrings)
+	if err != nil {
 		return nil, err
 	}
-	if rv, err = getNumber(r); err != nil {
+	high, err := compare(op, l, rng.High, compareStrings)
+	if err != nil {
 		return nil, err
 	}
-	return &ast.BooleanLiteral{Value: lv <= rv}, nil
+	in := low != unordered && high != unordered && low >= 0 && high <= 0
+	if rng.Exclusive {
+		in = in && low > 0 && high < 0
+	}
+	return &ast.BooleanLiteral{Value: in == (op == token.BETWEEN)}, nil
 }
 
-func applyLTE(l, r ast.Expr) (*ast.BooleanLiteral, error) {
-	var (
-		lv, rv float64
-		err    error
-	)
-	if lv, err = getNumber(l); err != nil {
+// Result of compare for NaN, neither before nor after anything
+const unordered = 2
+
+// Order operands of the same type: numbers by value, strings with
+// compareStrings, FALSE before TRUE, times and durations chronologically,
+// versions by semver precedence, IPv4 addresses before IPv6 ones
+func compare(op token.Token, l, r ast.Expr, compareStrings func(a, b string) int) (int, error) {
+	l, r = coerce(l, r)
+	switch lv := l.(type) {
+	case *ast.TimeLiteral:
+		rv, ok := r.(*ast.TimeLiteral)
+		if !ok {
+			return 0, mismatch(op, l, r)
+		}
+		return lv.Value.Compare(rv.Value), nil
+	case *ast.DurationLiteral:
+		rv, ok := r.(*ast.DurationLiteral)
+		if !ok {
+			return 0, mismatch(op, l, r)
+		}
+		switch {
+		case lv.Value < rv.Value:
+			return -1, nil
+		case lv.Value > rv.Value:
+			return 1, nil
+		}
+		return 0, nil
+	case *ast.VersionLiteral:
+		rv, ok := r.(*ast.VersionLiteral)
+		if !ok {
+			return 0, mismatch(op, l, r)
+		}
+		return lv.Value.Compare(rv.Value), nil
+	case *ast.IPLiteral:
+		rv, ok := r.(*ast.IPLiteral)
+		if !ok {
+			return 0, mismatch(op, l, r)
+		}
+		return lv.Value.Compare(rv.Value), nil
+	case *ast.NumberLiteral:
+		rv, err := getNumber(r)
+		if err != nil {
+			return 0, mismatch(op, l, r)
+		}
+		switch {
+		case lv.Value < rv:
+			return -1, nil
+		case lv.Value > rv:
+			return 1, nil
+		case lv.Value == rv:
+			return 0, nil
+		}
+		return unordered, nil
+	case *ast.StringLiteral:
+		rv, err := getString(r)
+		if err != nil {
+			return 0, mismatch(op, l, r)
+		}
+		return compareStrings(lv.Value, rv), nil
+	case *ast.BooleanLiteral:
+		rv, err := getBool(r)
+		if err != nil {
+			return 0, mismatch(op, l, r)
+		}
+		switch {
+		case lv.Value == rv:
+			return 0, nil
+		case rv:
+			return -1, nil
+		}
+		return 1, nil
+	}
+	return 0, mismatch(op, l, r)
+}
+
+func applyIN(l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	found, err := contains(token.IN, l, r)
+	if err != nil {
 		return nil, err
 	}
-	if rv, err = getNumber(r); err != nil {
+	return &ast.BooleanLiteral{Value: found}, nil
+}
+
+func applyNOTIN(l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	found, err := contains(token.NOTIN, l, r)
+	if err != nil {
 		return nil, err
 	}
-	return &ast.BooleanLiteral{Value: lv <= rv}, nil
+	return &ast.BooleanLiteral{Value: !found}, nil
 }
 
-func applyIN(l, r ast.Expr) (*ast.BooleanLiteral, error) {
-	var (
-		err error
-	)
-	switch t := l.(type) {
+// Equality of normalised JSON values, numbers compare by value whatever
+// their Go type
+func jsonEqual(l, r any) bool {
+	switch lv := l.(type) {
+	case map[string]any:
+		rv, ok := r.(map[string]any)
+		if !ok || len(lv) != len(rv) {
+			return false
+		}
+		for k, v := range lv {
+			if w, ok := rv[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		rv, ok := r.([]any)
+		if !ok || len(lv) != len(rv) {
+			return false
+		}
+		for i := range lv {
+			if !jsonEqual(lv[i], rv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	// Scalars
+	ln, lerr := toLiteral(l)
+	rn, rerr := toLiteral(r)
+	if lerr != nil || rerr != nil {
+		return reflect.DeepEqual(l, r)
+	}
+	if isNull(ln) || isNull(rn) {
+		return isNull(ln) && isNull(rn)
+	}
+	equal, err := compareEQ(token.EQ, ln, rn)
+	return err == nil && equal
+}
+
+// Result of a logical operator decided by its LHS alone
+func shortCircuit(op token.Token, l ast.Expr) (ast.Expr, bool) {
+	v, ok := l.(*ast.BooleanLiteral)
+	if !ok {
+		return nil, false
+	}
+	switch {
+	case op == token.AND && !v.Value, op == token.NAND && !v.Value:
+		return &ast.BooleanLiteral{Value: op == token.NAND}, true
+	case op == token.OR && v.Value:
+		return &ast.BooleanLiteral{Value: true}, true
+	}
+	return nil, false
+}
+
+func contains(op token.Token, l, r ast.Expr) (bool, error) {
+	if list, ok := r.(*ast.ListLiteral); ok {
+		return listContains(l, list), nil
+	}
+	switch l.(type) {
 	case *ast.StringLiteral:
-		var (
-			lv string
-			rv []string
-		)
-		lv, _ = getString(l)
-		if rv, err = getSliceString(r); err != nil {
-			return nil, err
+		lv, _ := getString(l)
+		rv, err := getSliceString(r)
+		if err != nil {
+			if isEmptySlice(r) {
+				return false, nil
+			}
+			return false, mismatch(op, l, r)
 		}
 		for _, v := range rv {
 			if lv == v {
-				return &ast.BooleanLiteral{Value: true}, nil
+				return true, nil
 			}
 		}
-		return &ast.BooleanLiteral{Value: false}, nil
+		return false, nil
 	case *ast.NumberLiteral:
-		var (
-			lv float64
-			rv []float64
-		)
-		lv, _ = getNumber(l)
-		if rv, err = getSliceNumber(r); err != nil {
-			return nil, err
+		lv, _ := getNumber(l)
+		rv, err := getSliceNumber(r)
+		if err != nil {
+			if isEmptySlice(r) {
+				return false, nil
+			}
+			return false, mismatch(op, l, r)
 		}
 		for _, v := range rv {
 			if lv == v {
-				return &ast.BooleanLiteral{Value: true}, nil
+				return true, nil
 			}
 		}
-		return &ast.BooleanLiteral{Value: false}, nil
+		return false, nil
+	case *ast.VersionLiteral:
+		// Members are version strings, other strings are never equal
+		lv := l.(*ast.VersionLiteral).Value
+		rv, err := getSliceString(r)
+		if err != nil {
+			if isEmptySlice(r) {
+				return false, nil
+			}
+			return false, mismatch(op, l, r)
+		}
+		for _, v := range rv {
+			if version, err := semver.Parse(v); err == nil && lv.Compare(version) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
 	default:
-		return nil, lerrors.Newf("Cannot evaluate Literal of unknow type %s %T", t, t)
-	}
-}
-
-func applyNOTIN(l, r ast.Expr) (*ast.BooleanLiteral, error) {
-	result, err := applyIN(l, r)
-	if err != nil {
-		return result, err
+		return false, mismatch(op, l, r)
 	}
-	result.Value = !result.Value
-	return result, err
 }
@@ -0,0 +1,124 @@
+package evaluator_test
+
+import (
+	"math"
+	"testing"
+
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/evaluator"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+type evalTest struct {
+	expr string
+	args map[string]any
+	opts []evaluator.Option
+	want bool
+	// Error wrapping err, errAny for any error
+	err error
+}
+
+func runEvalTests(t *testing.T, tests []evalTest) {
+	t.Helper()
+	for _, tt := range tests {
+		got, err := evaluator.Evaluate(parse(t, tt.expr), tt.args, tt.opts...)
+		switch {
+		case tt.err == nil && err != nil:
+			t.Errorf("%v with %v: %v", tt.expr, tt.args, err)
+		case tt.err != nil && (err == nil || tt.err != errAny && !lerrors.Is(err, tt.err)):
+			t.Errorf("%v with %v: got error %v, want %v", tt.expr, tt.args, err, tt.err)
+		case got != tt.want:
+			t.Errorf("%v with %v = %v, want %v", tt.expr, tt.args, got, tt.want)
+		}
+	}
+}
+
+func isMismatch(err error) bool {
+	var mismatch lerrors.ErrTypeMismatch
+	return lerrors.As(err, &mismatch)
+}
+
+func TestOrder(t *testing.T) {
+	german := []evaluator.Option{evaluator.WithCollation(language.German)}
+	runEvalTests(t, []evalTest{
+		// Strings byte-wise
+		{expr: `"a" < "b"`, want: true},
+		{expr: `"a" < "ab"`, want: true},
+		{expr: `"" < "a"`, want: true},
+		{expr: `"b" <= "b"`, want: true},
+		{expr: `"b" > "B"`, want: true},
+		{expr: `"Z" < "a"`, want: true},
+		{expr: `"é" > "f"`, want: true},
+		{expr: `[s] >= "m"`, args: map[string]any{"s": "n"}, want: true},
+		// With a collation
+		{expr: `"é" < "f"`, opts: german, want: true},
+		{expr: `"Z" > "a"`, opts: german, want: true},
+		{expr: `"a" < "B"`, opts: german, want: true},
+		{expr: `"a" == "A"`, opts: german, want: false},
+		{expr: `"a" >= "A"`, opts: []evaluator.Option{evaluator.WithCollation(language.English, collate.IgnoreCase)}, want: true},
+		{expr: `"a" <= "A"`, opts: []evaluator.Option{evaluator.WithCollation(language.English, collate.IgnoreCase)}, want: true},
+		// FALSE before TRUE
+		{expr: `FALSE < TRUE`, want: true},
+		{expr: `TRUE > FALSE`, want: true},
+		{expr: `TRUE <= TRUE`, want: true},
+		{expr: `TRUE < TRUE`, want: false},
+		{expr: `[b] >= FALSE`, args: map[string]any{"b": false}, want: true},
+		// Operands of different types
+		{expr: `"a" < 1`, err: errAny},
+		{expr: `1 > "a"`, err: errAny},
+		{expr: `TRUE > 0`, err: errAny},
+		{expr: `"true" > FALSE`, err: errAny},
+		{expr: `[xs] < 1`, args: map[string]any{"xs": []any{1.0}}, err: errAny},
+		{expr: `"a" < "b"`, opts: german, want: true},
+		{expr: `"a" < 1`, opts: german, err: errAny},
+	})
+	for _, expr := range []string{`"a" < 1`, `TRUE >= 1`, `"a" <= FALSE`} {
+		_, err := evaluator.Evaluate(parse(t, expr), nil)
+		if !isMismatch(err) {
+			t.Errorf("%v: got error %v, want ErrTypeMismatch", expr, err)
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	args := map[string]any{"nan": math.NaN(), "x": 5.0, "z": nil, "s": "m"}
+	tests := []struct {
+		expr string
+		opts []evaluator.Option
+		want string
+	}{
+		{expr: `[x] BETWEEN 1 AND 10`, want: "TRUE"},
+		{expr: `[x] BETWEEN 5 AND 5`, want: "TRUE"},
+		{expr: `[x] BETWEEN 5 AND 10 EXCLUSIVE`, want: "FALSE"},
+		{expr: `[x] BETWEEN 4 AND 6 EXCLUSIVE`, want: "TRUE"},
+		{expr: `[x] BETWEEN 5 AND 10 INCLUSIVE`, want: "TRUE"},
+		{expr: `[x] NOT BETWEEN 1 AND 10`, want: "FALSE"},
+		{expr: `[s] BETWEEN "a" AND "z"`, want: "TRUE"},
+		// Reversed bounds hold nothing
+		{expr: `[x] BETWEEN 10 AND 1`, want: "FALSE"},
+		{expr: `[x] NOT BETWEEN 10 AND 1`, want: "TRUE"},
+		{expr: `[x] BETWEEN 5 AND 5 EXCLUSIVE`, want: "FALSE"},
+		// NaN is neither in nor out of order, it is in no range
+		{expr: `[nan] BETWEEN 1 AND 10`, want: "FALSE"},
+		{expr: `[nan] NOT BETWEEN 1 AND 10`, want: "TRUE"},
+		{expr: `[x] BETWEEN [nan] AND 10`, want: "FALSE"},
+		{expr: `[x] BETWEEN 1 AND [nan]`, want: "FALSE"},
+		{expr: `[nan] BETWEEN [nan] AND [nan]`, want: "FALSE"},
+		// A NULL operand or bound is unknown
+		{expr: `[z] BETWEEN 1 AND 10`, want: "NULL"},
+		{expr: `[x] BETWEEN [z] AND 10`, want: "NULL"},
+		{expr: `[x] NOT BETWEEN 1 AND [z]`, want: "NULL"},
+		{expr: `[x] BETWEEN NULL AND NULL`, want: "NULL"},
+		{expr: `[x] BETWEEN [z] AND 10`, opts: []evaluator.Option{evaluator.WithMissing(evaluator.MissingFalse)}, want: "FALSE"},
+		{expr: `[missing] BETWEEN 1 AND 10`, opts: []evaluator.Option{evaluator.WithMissing(evaluator.MissingNull)}, want: "NULL"},
+		// Bounds of another type
+		{expr: `[x] BETWEEN "a" AND 10`, want: "error"},
+		{expr: `[s] BETWEEN 1 AND 10`, want: "error"},
+	}
+	for _, tt := range tests {
+		if got := outcome(t, tt.expr, args, tt.opts...); got != tt.want {
+			t.Errorf("%v = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
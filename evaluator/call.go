@@ -0,0 +1,47 @@
+package evaluator
+
+import (
+	"github.com/thenam153/conditions-go/ast"
+	lerrors "github.com/thenam153/conditions-go/errors"
+)
+
+// Evaluate a call of a built-in function
+func (ev *evaluation) evaluateCall(e *ast.CallExpr) (ast.Expr, error) {
+	args := make([]ast.Expr, len(e.Args))
+	for i, arg := range e.Args {
+		value, err := ev.evaluateTree(arg)
+		if err != nil {
+			return nil, lerrors.NewWrap("Cannot evaluate argument of "+e.Name, err)
+		}
+		args[i] = value
+	}
+	switch e.Name {
+	case "now":
+		return &ast.TimeLiteral{Value: ev.clock()}, nil
+	case "semver":
+		if isNull(args[0]) {
+			return &ast.NullLiteral{}, nil
+		}
+		return toVersion(args[0])
+	case "ip":
+		if isNull(args[0]) {
+			return &ast.NullLiteral{}, nil
+		}
+		return toIP(args[0])
+	case "dayOfWeek", "hour":
+		if isNull(args[0]) {
+			return &ast.NullLiteral{}, nil
+		}
+		_, arg := coerceTime(&ast.TimeLiteral{}, args[0])
+		t, ok := arg.(*ast.TimeLiteral)
+		if !ok {
+			return nil, lerrors.Newf("Function %v expects a time, got %v: %w", e.Name, typeName(arg), lerrors.ErrUnsupportedValue)
+		}
+		// Sunday is 0
+		if e.Name == "dayOfWeek" {
+			return &ast.NumberLiteral{Value: float64(t.Value.Weekday())}, nil
+		}
+		return &ast.NumberLiteral{Value: float64(t.Value.Hour())}, nil
+	}
+	return nil, lerrors.Newf("Unknown function %v", e.Name)
+}
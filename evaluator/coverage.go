@@ -0,0 +1,229 @@
+package evaluator
+
+import (
+	"sync"
+
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/token"
+)
+
+// Coverage records per-node outcome counts across evaluations of the same
+// parsed expressions. It is safe for concurrent use.
+type Coverage struct {
+	mu    sync.Mutex
+	nodes map[ast.Expr]*NodeCoverage
+}
+
+// NodeCoverage holds the outcome counts of a node. Operands counts the
+// operand combinations of a logical operator with both operands evaluated,
+// indexed by lhs<<1 | rhs. ShortCircuited counts the evaluations whose RHS
+// was skipped, indexed by lhs.
+type NodeCoverage struct {
+	Evaluated      int
+	True           int
+	False          int
+	Errors         int
+	Operands       [4]int
+	ShortCircuited [2]int
+}
+
+func NewCoverage() *Coverage {
+	return &Coverage{nodes: map[ast.Expr]*NodeCoverage{}}
+}
+
+// Node returns the counts recorded for expr
+func (c *Coverage) Node(expr ast.Expr) NodeCoverage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if nc, ok := c.nodes[expr]; ok {
+		return *nc
+	}
+	return NodeCoverage{}
+}
+
+// Reset removes all recorded counts
+func (c *Coverage) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nodes = map[ast.Expr]*NodeCoverage{}
+}
+
+func (c *Coverage) node(expr ast.Expr) *NodeCoverage {
+	nc, ok := c.nodes[expr]
+	if !ok {
+		nc = &NodeCoverage{}
+		c.nodes[expr] = nc
+	}
+	return nc
+}
+
+func (c *Coverage) record(expr ast.Expr, result ast.Expr, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	nc := c.node(expr)
+	nc.Evaluated++
+	if err != nil {
+		nc.Errors++
+		return
+	}
+	if v, ok := result.(*ast.BooleanLiteral); ok {
+		if v.Value {
+			nc.True++
+		} else {
+			nc.False++
+		}
+	}
+}
+
+// A nil r is a short-circuited RHS, it was not evaluated
+func (c *Coverage) recordOperands(expr ast.Expr, l, r ast.Expr) {
+	lv, lerr := getBool(l)
+	if lerr != nil {
+		return
+	}
+	if r == nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.node(expr).ShortCircuited[boolIndex(lv)]++
+		return
+	}
+	rv, rerr := getBool(r)
+	if rerr != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.node(expr).Operands[operandIndex(lv, rv)]++
+}
+
+func operandIndex(l, r bool) int {
+	return boolIndex(l)<<1 | boolIndex(r)
+}
+
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Gap is a coverage goal of a node never reached by the recorded evaluations
+type Gap struct {
+	Expr   ast.Expr
+	Reason string
+}
+
+// Summary counts coverage goals of an expression: every operator must be
+// seen true and false, every operand of a logical operator must be seen
+// deciding the outcome on its own (MC/DC).
+type Summary struct {
+	Goals   int
+	Covered int
+	Gaps    []Gap
+}
+
+func (s Summary) Percent() float64 {
+	if s.Goals == 0 {
+		return 100
+	}
+	return 100 * float64(s.Covered) / float64(s.Goals)
+}
+
+// Summary returns the coverage goals of expr, gaps are ordered by position
+func (c *Coverage) Summary(expr ast.Expr) Summary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := Summary{}
+	c.summarize(expr, &s)
+	return s
+}
+
+func (c *Coverage) summarize(expr ast.Expr, s *Summary) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		c.summarize(e.Expr, s)
+	case *ast.BinaryExpr:
+		// Arithmetic computes a value, it has no outcome to cover
+		if e.OP.IsArithmetic() {
+			break
+		}
+		goals := []string{"never true", "never false"}
+		if e.OP.IsLogical() {
+			goals = append(goals, "LHS never decided the outcome", "RHS never decided the outcome")
+		}
+		s.Goals += len(goals)
+		nc, ok := c.nodes[e]
+		if !ok || nc.Evaluated == 0 {
+			s.Gaps = append(s.Gaps, Gap{Expr: e, Reason: "never evaluated"})
+		} else {
+			covered := []bool{nc.True > 0, nc.False > 0}
+			if e.OP.IsLogical() {
+				covered = append(covered, independent(e.OP, *nc, true), independent(e.OP, *nc, false))
+			}
+			for i, ok := range covered {
+				if ok {
+					s.Covered++
+				} else {
+					s.Gaps = append(s.Gaps, Gap{Expr: e, Reason: goals[i]})
+				}
+			}
+		}
+		c.summarize(e.LHS, s)
+		c.summarize(e.RHS, s)
+	case *ast.UnaryExpr, *ast.QuantifierExpr:
+		s.Goals += 2
+		nc, ok := c.nodes[e]
+		switch {
+		case !ok || nc.Evaluated == 0:
+			s.Gaps = append(s.Gaps, Gap{Expr: e, Reason: "never evaluated"})
+		default:
+			for i, n := range []int{nc.True, nc.False} {
+				if n > 0 {
+					s.Covered++
+				} else {
+					s.Gaps = append(s.Gaps, Gap{Expr: e, Reason: []string{"never true", "never false"}[i]})
+				}
+			}
+		}
+		// Conditions of a quantifier are covered across all elements
+		if q, ok := e.(*ast.QuantifierExpr); ok {
+			c.summarize(q.Body, s)
+		}
+	}
+}
+
+// Operand independence: flipping only this operand flips the outcome
+// between two recorded evaluations. A skipped RHS did not decide the outcome,
+// it pairs with either RHS value to show the LHS independent, never to show
+// the RHS independent.
+func independent(op token.Token, nc NodeCoverage, lhs bool) bool {
+	seen := func(l, r bool) bool {
+		return nc.Operands[operandIndex(l, r)] > 0 || (lhs && nc.ShortCircuited[boolIndex(l)] > 0)
+	}
+	for _, other := range []bool{false, true} {
+		t, f := seen(true, other), seen(false, other)
+		rt, rf := logical(op, true, other), logical(op, false, other)
+		if !lhs {
+			t, f = seen(other, true), seen(other, false)
+			rt, rf = logical(op, other, true), logical(op, other, false)
+		}
+		if t && f && rt != rf {
+			return true
+		}
+	}
+	return false
+}
+
+func logical(op token.Token, l, r bool) bool {
+	switch op {
+	case token.AND:
+		return l && r
+	case token.NAND:
+		return !(l && r)
+	case token.OR:
+		return l || r
+	case token.XOR:
+		return l != r
+	}
+	return false
+}
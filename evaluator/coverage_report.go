@@ -0,0 +1,153 @@
+package evaluator
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/thenam153/conditions-go/ast"
+)
+
+// CoverageSource is a parsed rule with the source it was parsed from, node
+// spans of Expr must point into Source.
+type CoverageSource struct {
+	Name   string
+	Source string
+	Expr   ast.Expr
+}
+
+// WriteText writes coverage of every source with the uncovered sub-expressions
+//
+//	adult: 5/6 goals covered (83.3%)
+//	  1:1 [age] >= 18 AND [name] == "bob": RHS never decided the outcome
+func (c *Coverage) WriteText(w io.Writer, sources ...CoverageSource) error {
+	for _, src := range sources {
+		s := c.Summary(src.Expr)
+		if _, err := fmt.Fprintf(w, "%v: %d/%d goals covered (%.1f%%)\n", src.Name, s.Covered, s.Goals, s.Percent()); err != nil {
+			return err
+		}
+		for _, gap := range s.Gaps {
+			span := ast.Position(gap.Expr)
+			line, col := lineColumn(src.Source, span.Start)
+			if _, err := fmt.Fprintf(w, "  %d:%d %v: %v\n", line, col, sourceText(src.Source, span), gap.Reason); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteHTML writes a standalone page showing the source of every rule with
+// covered operators in green, partially covered in yellow and never
+// evaluated in red. Reasons are shown as tooltips.
+func (c *Coverage) WriteHTML(w io.Writer, sources ...CoverageSource) error {
+	var sb strings.Builder
+	sb.WriteString(coverageHTMLHead)
+	for _, src := range sources {
+		s := c.Summary(src.Expr)
+		gaps := map[ast.Expr][]string{}
+		for _, gap := range s.Gaps {
+			gaps[gap.Expr] = append(gaps[gap.Expr], gap.Reason)
+		}
+		fmt.Fprintf(&sb, "<h2>%v <small>%d/%d goals covered (%.1f%%)</small></h2>\n<pre>",
+			html.EscapeString(src.Name), s.Covered, s.Goals, s.Percent())
+		end := renderCoverage(&sb, src.Source, src.Expr, 0, gaps)
+		sb.WriteString(html.EscapeString(src.Source[end:]))
+		sb.WriteString("</pre>\n")
+	}
+	sb.WriteString(coverageHTMLFoot)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Render source from pos up to the end of expr, return the end offset
+func renderCoverage(sb *strings.Builder, src string, expr ast.Expr, pos int, gaps map[ast.Expr][]string) int {
+	span := ast.Position(expr)
+	if span.Start < pos || span.End > len(src) || span.Start > span.End {
+		return pos
+	}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		sb.WriteString(html.EscapeString(src[pos:span.Start]))
+		pos = renderCoverage(sb, src, e.Expr, span.Start, gaps)
+	case *ast.BinaryExpr:
+		if e.OP.IsArithmetic() {
+			return pos
+		}
+		sb.WriteString(html.EscapeString(src[pos:span.Start]))
+		openCoverage(sb, gaps[e])
+		pos = renderCoverage(sb, src, e.LHS, span.Start, gaps)
+		pos = renderCoverage(sb, src, e.RHS, pos, gaps)
+		sb.WriteString(html.EscapeString(src[pos:span.End]))
+		sb.WriteString("</span>")
+		return span.End
+	case *ast.UnaryExpr:
+		sb.WriteString(html.EscapeString(src[pos:span.Start]))
+		openCoverage(sb, gaps[e])
+		pos = renderCoverage(sb, src, e.Expr, span.Start, gaps)
+		sb.WriteString(html.EscapeString(src[pos:span.End]))
+		sb.WriteString("</span>")
+		return span.End
+	case *ast.QuantifierExpr:
+		sb.WriteString(html.EscapeString(src[pos:span.Start]))
+		openCoverage(sb, gaps[e])
+		pos = renderCoverage(sb, src, e.Body, span.Start, gaps)
+		sb.WriteString(html.EscapeString(src[pos:span.End]))
+		sb.WriteString("</span>")
+		return span.End
+	default:
+		return pos
+	}
+	sb.WriteString(html.EscapeString(src[pos:span.End]))
+	return span.End
+}
+
+func openCoverage(sb *strings.Builder, reasons []string) {
+	class, title := "covered", "covered"
+	if len(reasons) > 0 {
+		class, title = "partial", strings.Join(reasons, ", ")
+		if len(reasons) == 1 && reasons[0] == "never evaluated" {
+			class = "uncovered"
+		}
+	}
+	fmt.Fprintf(sb, `<span class="%v" title="%v">`, class, html.EscapeString(title))
+}
+
+func sourceText(src string, span ast.Span) string {
+	if span.Start < 0 || span.End > len(src) || span.Start >= span.End {
+		return ""
+	}
+	return src[span.Start:span.End]
+}
+
+// Line and column (1-based, in bytes) of offset in src
+func lineColumn(src string, offset int) (int, int) {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	line := 1 + strings.Count(src[:offset], "\n")
+	return line, offset - strings.LastIndex(src[:offset], "\n")
+}
+
+const coverageHTMLHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Condition coverage</title>
+<style>
+body { font-family: sans-serif; }
+pre { background: #f6f8fa; padding: 8px; white-space: pre-wrap; }
+small { color: #666; font-weight: normal; }
+.covered { background: #d4f8d4; }
+.partial { background: #fff3b0; }
+.uncovered { background: #ffc8c8; }
+</style>
+</head>
+<body>
+<h1>Condition coverage</h1>
+`
+
+const coverageHTMLFoot = `</body>
+</html>
+`
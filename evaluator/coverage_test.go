@@ -0,0 +1,110 @@
+package evaluator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/evaluator"
+	"github.com/thenam153/conditions-go/parser"
+)
+
+const coverageRule = `[age] >= 18 AND [name] == "bob"`
+
+func coverageRun(t *testing.T, src string, args ...map[string]any) (*evaluator.Coverage, ast.Expr) {
+	t.Helper()
+	expr, err := parser.NewParser(strings.NewReader(src)).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cov := evaluator.NewCoverage()
+	for _, a := range args {
+		if _, err := evaluator.Evaluate(expr, a, evaluator.WithCoverage(cov)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cov, expr
+}
+
+func TestCoverageShortCircuit(t *testing.T) {
+	cov, expr := coverageRun(t, coverageRule, map[string]any{"age": 10})
+	// The skipped RHS took neither value
+	nc := cov.Node(expr)
+	if nc.Operands != [4]int{} || nc.ShortCircuited != [2]int{1, 0} {
+		t.Errorf("Operands = %v, ShortCircuited = %v, want none and one with a false LHS", nc.Operands, nc.ShortCircuited)
+	}
+	if s := cov.Summary(expr); s.Covered != 2 || s.Goals != 8 {
+		t.Errorf("Summary() = %d/%d, want 2/8: %v", s.Covered, s.Goals, s.Gaps)
+	}
+
+	// A skipped RHS pairs with an evaluated one to show the LHS independent
+	cov, expr = coverageRun(t, coverageRule,
+		map[string]any{"age": 10}, map[string]any{"age": 20, "name": "bob"}, map[string]any{"age": 20, "name": "al"})
+	if s := cov.Summary(expr); s.Covered != s.Goals || len(s.Gaps) != 0 {
+		t.Errorf("Summary() = %d/%d, want all covered: %v", s.Covered, s.Goals, s.Gaps)
+	}
+
+	// but never the RHS
+	cov, expr = coverageRun(t, `[a] OR [b]`, map[string]any{"a": true}, map[string]any{"a": false, "b": false})
+	s := cov.Summary(expr)
+	if len(s.Gaps) != 1 || s.Gaps[0].Reason != "RHS never decided the outcome" {
+		t.Errorf("Summary() gaps = %v, want the RHS only", s.Gaps)
+	}
+}
+
+func TestCoverageWriteText(t *testing.T) {
+	src := "[age] >= 18\n  AND [name] == \"bob\""
+	cov, expr := coverageRun(t, src, map[string]any{"age": 10})
+	var sb strings.Builder
+	if err := cov.WriteText(&sb, evaluator.CoverageSource{Name: "adult", Source: src, Expr: expr}); err != nil {
+		t.Fatal(err)
+	}
+	want := `adult: 2/8 goals covered (25.0%)
+  1:1 [age] >= 18
+  AND [name] == "bob": never true
+  1:1 [age] >= 18
+  AND [name] == "bob": LHS never decided the outcome
+  1:1 [age] >= 18
+  AND [name] == "bob": RHS never decided the outcome
+  1:1 [age] >= 18: never true
+  2:7 [name] == "bob": never evaluated
+`
+	if got := sb.String(); got != want {
+		t.Errorf("WriteText() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestCoverageWriteHTML(t *testing.T) {
+	tests := []struct {
+		name, src string
+		args      []map[string]any
+		want      string
+	}{
+		{
+			name: "<adult>", src: coverageRule, args: []map[string]any{{"age": 10}},
+			want: "<h2>&lt;adult&gt; <small>2/8 goals covered (25.0%)</small></h2>\n" +
+				`<pre><span class="partial" title="never true, LHS never decided the outcome, RHS never decided the outcome">` +
+				`<span class="partial" title="never true">[age] &gt;= 18</span> AND ` +
+				`<span class="uncovered" title="never evaluated">[name] == &#34;bob&#34;</span></span></pre>`,
+		},
+		{
+			name: "small", src: `([a] < 1) `, args: []map[string]any{{"a": 0}, {"a": 2}},
+			want: "<h2>small <small>2/2 goals covered (100.0%)</small></h2>\n" +
+				`<pre>(<span class="covered" title="covered">[a] &lt; 1</span>) </pre>`,
+		},
+	}
+	for _, tt := range tests {
+		cov, expr := coverageRun(t, tt.src, tt.args...)
+		var sb strings.Builder
+		if err := cov.WriteHTML(&sb, evaluator.CoverageSource{Name: tt.name, Source: tt.src, Expr: expr}); err != nil {
+			t.Fatal(err)
+		}
+		got := sb.String()
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("WriteHTML() does not contain\n%v\ngot\n%v", tt.want, got)
+		}
+		if !strings.HasPrefix(got, "<!DOCTYPE html>") || !strings.HasSuffix(got, "</html>\n") {
+			t.Errorf("WriteHTML() is not a page: %v", got)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/token"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Apply operator, string equality of ==, !=, IN, NOT IN and the set
+// operators, array elements included, and string ordering follow the options
+func (ev *evaluation) applyOperator(op token.Token, l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	switch op {
+	case token.EQI, token.NOTEQI:
+		return applyEqualFold(op, l, r)
+	case token.EQ, token.NEQ, token.IN, token.NOTIN, token.INTERSECTS, token.DISJOINT,
+		token.SUBSETOF, token.NOTSUBSETOF, token.SUPERSETOF, token.NOTSUPERSETOF:
+		if ev.opts.caseFolding || ev.opts.normalize != nil {
+			// Strings parsed as times, versions or addresses keep their case
+			l, r = coerce(l, r)
+			return applyOperator(op, ev.canonical(l), ev.canonical(r))
+		}
+	case token.GT, token.GTE, token.LT, token.LTE, token.BETWEEN, token.NOTBETWEEN:
+		if ev.opts.collation != nil {
+			if ev.collator == nil {
+				ev.collator = collate.New(ev.opts.collation.tag, ev.opts.collation.opts...)
+			}
+			if op == token.BETWEEN || op == token.NOTBETWEEN {
+				return applyBetween(op, l, r, ev.collator.CompareString)
+			}
+			return applyOrder(op, l, r, ev.collator.CompareString)
+		}
+	}
+	return applyOperator(op, l, r)
+}
+
+// Strings of e, a string or an array, in the normalisation form and case
+// folded as the options ask. Strings are equal under the options when their
+// canonical forms are.
+func (ev *evaluation) canonical(e ast.Expr) ast.Expr {
+	switch v := e.(type) {
+	case *ast.StringLiteral:
+		return &ast.StringLiteral{Span: v.Span, Value: ev.canonicalString(v.Value)}
+	case *ast.SliceStringLiteral:
+		strs := make([]string, len(v.Value))
+		for i, s := range v.Value {
+			strs[i] = ev.canonicalString(s)
+		}
+		return &ast.SliceStringLiteral{Span: v.Span, Value: strs}
+	case *ast.ListLiteral:
+		elems := make([]ast.Expr, len(v.Value))
+		for i, elem := range v.Value {
+			elems[i] = ev.canonical(elem)
+		}
+		return &ast.ListLiteral{Span: v.Span, Value: elems}
+	}
+	return e
+}
+
+func (ev *evaluation) canonicalString(s string) string {
+	if ev.opts.caseFolding {
+		if ev.folder == nil {
+			folder := cases.Fold()
+			ev.folder = &folder
+		}
+		s = ev.folder.String(s)
+	}
+	if f := ev.opts.normalize; f != nil {
+		s = f.String(s)
+	}
+	return s
+}
+
+// Apply EQI or NOT EQI, strings are equal when their NFC forms are equal under
+// case folding, other types are compared as usual
+func applyEqualFold(op token.Token, l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	l, r = coerce(l, r)
+	lv, err := getString(l)
+	if err != nil {
+		found, err := compareEQ(op, l, r)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.BooleanLiteral{Value: found == (op == token.EQI)}, nil
+	}
+	rv, err := getString(r)
+	if err != nil {
+		return nil, mismatch(op, l, r)
+	}
+	found := strings.EqualFold(norm.NFC.String(lv), norm.NFC.String(rv))
+	return &ast.BooleanLiteral{Value: found == (op == token.EQI)}, nil
+}
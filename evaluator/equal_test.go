@@ -0,0 +1,60 @@
+package evaluator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thenam153/conditions-go/evaluator"
+	"github.com/thenam153/conditions-go/parser"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Scalars, array elements and the set operators compare strings alike under
+// the same options
+func TestEqualOptions(t *testing.T) {
+	const (
+		nfc = "caf\u00e9"
+		nfd = "cafe\u0301"
+	)
+	tests := []struct {
+		expr string
+		args map[string]any
+		opts []evaluator.Option
+		want bool
+	}{
+		{expr: `[s] == "STRASSE"`, args: map[string]any{"s": "Straße"}, want: false},
+		{expr: `[s] == "STRASSE"`, args: map[string]any{"s": "Straße"}, opts: []evaluator.Option{evaluator.WithCaseFolding()}, want: true},
+		{expr: `[s] != "STRASSE"`, args: map[string]any{"s": "Straße"}, opts: []evaluator.Option{evaluator.WithCaseFolding()}, want: false},
+		{expr: `[a] == ["STRASSE", "x"]`, args: map[string]any{"a": []any{"Straße", "X"}}, want: false},
+		{expr: `[a] == ["STRASSE", "x"]`, args: map[string]any{"a": []any{"Straße", "X"}}, opts: []evaluator.Option{evaluator.WithCaseFolding()}, want: true},
+		{expr: `[a] != ["STRASSE"]`, args: map[string]any{"a": []any{"Straße"}}, opts: []evaluator.Option{evaluator.WithCaseFolding()}, want: false},
+		{expr: `[s] IN ["STRASSE", "x"]`, args: map[string]any{"s": "Straße"}, opts: []evaluator.Option{evaluator.WithCaseFolding()}, want: true},
+		{expr: `[s] NOT IN ["STRASSE"]`, args: map[string]any{"s": "Straße"}, opts: []evaluator.Option{evaluator.WithCaseFolding()}, want: false},
+		{expr: `"STRASSE" IN [a]`, args: map[string]any{"a": []any{"Straße"}}, opts: []evaluator.Option{evaluator.WithCaseFolding()}, want: true},
+		{expr: `[s] IN ["STRASSE", 1]`, args: map[string]any{"s": "Straße"}, opts: []evaluator.Option{evaluator.WithCaseFolding()}, want: true},
+		{expr: `[a] INTERSECTS ["STRASSE"]`, args: map[string]any{"a": []any{"Straße"}}, opts: []evaluator.Option{evaluator.WithCaseFolding()}, want: true},
+		{expr: `[a] DISJOINT ["STRASSE"]`, args: map[string]any{"a": []any{"Straße"}}, opts: []evaluator.Option{evaluator.WithCaseFolding()}, want: false},
+		{expr: `[a] SUBSET OF ["STRASSE", "B"]`, args: map[string]any{"a": []any{"Straße", "b"}}, opts: []evaluator.Option{evaluator.WithCaseFolding()}, want: true},
+		{expr: `[a] SUPERSET OF ["STRASSE"]`, args: map[string]any{"a": []any{"Straße", "b"}}, opts: []evaluator.Option{evaluator.WithCaseFolding()}, want: true},
+		{expr: `[s] == [t]`, args: map[string]any{"s": nfc, "t": nfd}, want: false},
+		{expr: `[s] == [t]`, args: map[string]any{"s": nfc, "t": nfd}, opts: []evaluator.Option{evaluator.WithNormalization(norm.NFC)}, want: true},
+		{expr: `[a] == [b]`, args: map[string]any{"a": []any{nfc}, "b": []any{nfd}}, opts: []evaluator.Option{evaluator.WithNormalization(norm.NFC)}, want: true},
+		{expr: `[s] IN [b]`, args: map[string]any{"s": nfc, "b": []any{nfd}}, opts: []evaluator.Option{evaluator.WithNormalization(norm.NFC)}, want: true},
+		{expr: `[a] INTERSECTS [b]`, args: map[string]any{"a": []any{nfc}, "b": []any{nfd}}, opts: []evaluator.Option{evaluator.WithNormalization(norm.NFC)}, want: true},
+		{expr: `[a] == [b]`, args: map[string]any{"a": []any{"CAFÉ"}, "b": []any{nfd}}, opts: []evaluator.Option{evaluator.WithCaseFolding(), evaluator.WithNormalization(norm.NFC)}, want: true},
+	}
+	for _, tt := range tests {
+		expr, err := parser.NewParser(strings.NewReader(tt.expr)).Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := evaluator.Evaluate(expr, tt.args, tt.opts...)
+		if err != nil {
+			t.Errorf("%v with %v: %v", tt.expr, tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v with %v and %d options = %v, want %v", tt.expr, tt.args, len(tt.opts), got, tt.want)
+		}
+	}
+}
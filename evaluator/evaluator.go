@@ -1,178 +1,206 @@
 package evaluator
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"reflect"
+	"time"
 
 	"github.com/thenam153/conditions-go/ast"
 	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/token"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/collate"
 )
 
-func Evaluate(expr ast.Expr, args map[string]any) (bool, error) {
-	expr, err := evaluateTree(expr, args)
+// Evaluate expression with args, options may be given to inspect or tune the evaluation
+func Evaluate(expr ast.Expr, args map[string]any, opts ...Option) (bool, error) {
+	return evaluate(context.Background(), expr, args, opts)
+}
+
+// EvaluateContext is Evaluate stopped with the context error once ctx is done,
+// JQ queries included
+func EvaluateContext(ctx context.Context, expr ast.Expr, args map[string]any, opts ...Option) (bool, error) {
+	return evaluate(ctx, expr, args, opts)
+}
+
+// EvaluateStruct evaluates expression with the fields of a struct, or a
+// pointer to a struct, as args. [field] is resolved by the cond tag, then
+// the json tag, then the field name.
+func EvaluateStruct(expr ast.Expr, v any, opts ...Option) (bool, error) {
+	if rv := indirect(reflect.ValueOf(v)); !rv.IsValid() || rv.Kind() != reflect.Struct {
+		return false, lerrors.Newf("Cannot evaluate with %T, expected a struct: %w", v, lerrors.ErrUnsupportedValue)
+	}
+	return evaluate(context.Background(), expr, v, opts)
+}
+
+// EvaluateResolver evaluates expression with args fetched from r when, and
+// only when, the evaluation needs them. JQ queries require r to implement
+// DocumentResolver.
+func EvaluateResolver(expr ast.Expr, r Resolver, opts ...Option) (bool, error) {
+	if r == nil {
+		return false, lerrors.New("Resolver must be not nil")
+	}
+	return evaluate(context.Background(), expr, r, opts)
+}
+
+func evaluate(ctx context.Context, expr ast.Expr, args any, opts []Option) (bool, error) {
+	ev := newEvaluation(ctx, args, opts)
+	expr, err := ev.evaluateTree(expr)
 	if err != nil {
 		return false, lerrors.NewWrap("Cannot evaluate expression", err)
 	}
-	if v, ok := expr.(*ast.BooleanLiteral); ok {
+	switch v := expr.(type) {
+	case *ast.BooleanLiteral:
 		return v.Value, nil
+	case *ast.NullLiteral:
+		// Unknown result of three-valued logic does not match
+		return false, nil
 	}
 	return false, lerrors.Newf("Wrong root expression, cannot return boolean value, type: %T", expr)
 }
 
-func evaluateTree(expr ast.Expr, args map[string]any) (ast.Expr, error) {
+type evaluation struct {
+	ctx context.Context
+	// A map[string]any, a struct or a Resolver
+	args     any
+	resolved map[string]resolved
+	doc      any
+	docReady bool
+	// Collator of WithCollation, not safe for concurrent use
+	collator *collate.Collator
+	// Full case folding of WithCaseFolding, not safe for concurrent use
+	folder *cases.Caser
+	opts   options
+	depth  int
+	nodes  int
+	// Operand of IS NULL, a missing argument is NULL whatever the option
+	nullable bool
+	// Time of now(), read from the clock once
+	now    time.Time
+	nowSet bool
+	// Elements bound by the quantifiers being evaluated
+	bound map[string]any
+}
+
+func newEvaluation(ctx context.Context, args any, opts []Option) *evaluation {
+	ev := &evaluation{ctx: ctx, args: args}
+	for _, opt := range opts {
+		opt(&ev.opts)
+	}
+	return ev
+}
+
+func (ev *evaluation) evaluateTree(expr ast.Expr) (ast.Expr, error) {
+	if err := ev.check(); err != nil {
+		return nil, err
+	}
+	step := 0
+	if ev.opts.trace != nil {
+		step = ev.opts.trace.begin(expr, ev.depth)
+	}
+	ev.depth++
+	result, err := ev.evaluateNode(expr)
+	ev.depth--
+	if ev.opts.trace != nil {
+		ev.opts.trace.end(step, result, err)
+	}
+	if ev.opts.coverage != nil {
+		ev.opts.coverage.record(expr, result, err)
+	}
+	return result, err
+}
+
+// Check limits and cancellation before evaluating a node
+func (ev *evaluation) check() error {
+	ev.nodes++
+	if max := ev.opts.maxNodes; max > 0 && ev.nodes > max {
+		return lerrors.Newf("Cannot evaluate more than %d nodes: %w", max, lerrors.ErrLimitExceeded)
+	}
+	if max := ev.opts.maxDepth; max > 0 && ev.depth >= max {
+		return lerrors.Newf("Cannot evaluate expression deeper than %d: %w", max, lerrors.ErrLimitExceeded)
+	}
+	if err := ev.ctx.Err(); err != nil {
+		return lerrors.NewWrap("Evaluation stopped", err)
+	}
+	return nil
+}
+
+func (ev *evaluation) evaluateNode(expr ast.Expr) (ast.Expr, error) {
 	if expr == nil || reflect.ValueOf(expr).IsNil() {
 		return nil, lerrors.New("Expression must be not nil")
 	}
 	switch e := expr.(type) {
 	case *ast.ParenExpr:
-		return evaluateTree(e.Expr, args)
+		return ev.evaluateTree(e.Expr)
+	case *ast.UnaryExpr:
+		return ev.evaluateUnary(e)
 	case *ast.BinaryExpr:
+		// [a] == NULL is [a] IS NULL, not an unknown comparison
+		if operand, ok := nullComparison(e); ok {
+			return ev.evaluateIsNull(operand, e.OP == token.EQ)
+		}
 		var (
 			elhs, erhs ast.Expr
 			err        error
 		)
-		if elhs, err = evaluateTree(e.LHS, args); err != nil {
+		if elhs, err = ev.evaluateTree(e.LHS); err != nil {
 			return nil, lerrors.NewWrap("Cannot evaluate LHS of binary expression", err)
 		}
-		if erhs, err = evaluateTree(e.RHS, args); err != nil {
-			return nil, lerrors.NewWrap("Cannot evaluate RHS of binary expression", err)
+		// RHS is not evaluated, nor its arguments resolved, once LHS decides
+		if result, ok := shortCircuit(e.OP, elhs); ok {
+			if ev.opts.coverage != nil {
+				ev.opts.coverage.recordOperands(e, elhs, nil)
+			}
+			return result, nil
 		}
-		return applyOperator(e.OP, elhs, erhs)
-	case *ast.VarRef:
-		index := e.Value
-		if _, ok := args[index]; !ok {
-			return nil, lerrors.Newf("Cannot get args with index %v", index)
+		if erhs, err = ev.evaluateTree(e.RHS); err != nil {
+			return nil, lerrors.NewWrap("Cannot evaluate RHS of binary expression", err)
 		}
-		kind := reflect.TypeOf(args[index]).Kind()
-		switch kind {
-		case reflect.Int:
-			return &ast.NumberLiteral{Value: float64(args[index].(int))}, nil
-		case reflect.Int32:
-			return &ast.NumberLiteral{Value: float64(args[index].(int32))}, nil
-		case reflect.Int64:
-			return &ast.NumberLiteral{Value: float64(args[index].(int64))}, nil
-		case reflect.Float32:
-			return &ast.NumberLiteral{Value: float64(args[index].(float32))}, nil
-		case reflect.Float64:
-			return &ast.NumberLiteral{Value: float64(args[index].(float64))}, nil
-		case reflect.String:
-			return &ast.StringLiteral{Value: args[index].(string)}, nil
-		case reflect.Bool:
-			return &ast.BooleanLiteral{Value: args[index].(bool)}, nil
-		case reflect.Slice, reflect.Array:
-			kindEl := reflect.TypeOf(args[index]).Elem().Kind()
-			switch kindEl {
-			case reflect.Int:
-				arrValue := args[index].([]int)
-				arrNumber := make([]float64, len(arrValue))
-				for _, v := range arrValue {
-					arrNumber = append(arrNumber, float64(v))
-				}
-				return &ast.SliceNumberLiteral{Value: arrNumber}, nil
-			case reflect.Int32:
-				arrValue := args[index].([]int32)
-				arrNumber := make([]float64, len(arrValue))
-				for _, v := range arrValue {
-					arrNumber = append(arrNumber, float64(v))
-				}
-				return &ast.SliceNumberLiteral{Value: arrNumber}, nil
-			case reflect.Int64:
-				arrValue := args[index].([]int64)
-				arrNumber := make([]float64, len(arrValue))
-				for _, v := range arrValue {
-					arrNumber = append(arrNumber, float64(v))
-				}
-				return &ast.SliceNumberLiteral{Value: arrNumber}, nil
-			case reflect.Float32:
-				arrValue := args[index].([]float32)
-				arrNumber := make([]float64, len(arrValue))
-				for _, v := range arrValue {
-					arrNumber = append(arrNumber, float64(v))
-				}
-				return &ast.SliceNumberLiteral{Value: arrNumber}, nil
-			case reflect.Float64:
-				arrNumber := args[index].([]float64)
-				return &ast.SliceNumberLiteral{Value: arrNumber}, nil
-			case reflect.String:
-				arrString := args[index].([]string)
-				return &ast.SliceStringLiteral{Value: arrString}, nil
-			default:
-				arrString := args[index].([]string)
-				return &ast.SliceStringLiteral{Value: arrString}, nil
-			}
+		if ev.opts.coverage != nil && e.OP.IsLogical() {
+			ev.opts.coverage.recordOperands(e, elhs, erhs)
 		}
-		return nil, lerrors.Newf("Cannot parse %T to array", expr)
-	case *ast.JQRef:
-		var (
-			bytes  []byte
-			err    error
-			value  any
-			values []any
-		)
-		if bytes, err = json.Marshal(args); err != nil {
-			return nil, lerrors.Wrap(fmt.Errorf("cannot marshal %T to JSON", expr), err)
+		if isNull(elhs) || isNull(erhs) {
+			return ev.applyNull(e.OP, elhs, erhs)
 		}
-		if err = json.Unmarshal(bytes, &value); err != nil {
-			return nil, lerrors.Wrap(fmt.Errorf("cannot unmarshal %T to any", expr), err)
+		if e.OP.IsArithmetic() {
+			return applyArithmetic(e.OP, elhs, erhs)
 		}
-		iter := e.Query.Run(value)
-		for {
-			v, n := iter.Next()
-			values = append(values, v)
-			if !n {
-				break
-			}
+		return ev.applyOperator(e.OP, elhs, erhs)
+	case *ast.VarRef:
+		value, ok, err := ev.lookup(e.Value)
+		if err != nil {
+			return nil, lerrors.NewWrap("Cannot resolve args with index "+e.Value, err)
 		}
-		jqMode, ok := ast.JQModes[e.Mode]
 		if !ok {
-			jqMode = ast.JQFirst
-		}
-		switch jqMode {
-		case ast.JQFirst:
-			switch v := values[0].(type) {
-			case float64:
-				return &ast.NumberLiteral{Value: v}, nil
-			case string:
-				return &ast.StringLiteral{Value: v}, nil
-			case nil:
-				return nil, lerrors.New("JQ Query get nil value")
-			default:
-				return nil, lerrors.Newf("JQ unsupported type %T", v)
-			}
-		case ast.JQLast:
-			switch v := values[len(values)-1].(type) {
-			case float64:
-				return &ast.NumberLiteral{Value: v}, nil
-			case string:
-				return &ast.StringLiteral{Value: v}, nil
-			case nil:
-				return nil, lerrors.New("JQ Query get nil value")
-			default:
-				return nil, lerrors.Newf("JQ unsupported type %T", v)
-			}
-		case ast.JQArray:
-			switch v := values[0].(type) {
-			case float64:
-				arrayNumber := []float64{}
-				for _, v := range values {
-					arrayNumber = append(arrayNumber, v.(float64))
-				}
-				return &ast.SliceNumberLiteral{Value: arrayNumber}, nil
-			case string:
-				arrayString := []string{}
-				for _, v := range values {
-					arrayString = append(arrayString, v.(string))
-				}
-				return &ast.SliceStringLiteral{Value: arrayString}, nil
-			case nil:
-				return nil, lerrors.New("JQ Query get nil value")
-			default:
-				return nil, lerrors.Newf("JQ unsupported type %T", v)
-			}
-		default:
-			return nil, lerrors.Newf("Not implemented JQMode, JQMode: %v", jqMode)
+			return ev.missing(e.Value, lerrors.Newf("Cannot get args with index %v: %w", e.Value, lerrors.ErrUnknownVariable))
 		}
+		return toLiteral(value)
+	case *ast.JQRef:
+		return ev.evaluateJQ(e)
+	case *ast.CallExpr:
+		return ev.evaluateCall(e)
+	case *ast.QuantifierExpr:
+		return ev.evaluateQuantifier(e)
+	case *ast.RangeExpr:
+		return ev.evaluateRange(e)
+	case *ast.BoundRef:
+		return ev.evaluateBound(e)
 	}
 	return expr, nil
 }
+
+// Evaluate the bounds of a range, a range with a NULL bound is NULL
+func (ev *evaluation) evaluateRange(e *ast.RangeExpr) (ast.Expr, error) {
+	low, err := ev.evaluateTree(e.Low)
+	if err != nil {
+		return nil, lerrors.NewWrap("Cannot evaluate lower bound of range", err)
+	}
+	high, err := ev.evaluateTree(e.High)
+	if err != nil {
+		return nil, lerrors.NewWrap("Cannot evaluate upper bound of range", err)
+	}
+	if isNull(low) || isNull(high) {
+		return &ast.NullLiteral{}, nil
+	}
+	return &ast.RangeExpr{Low: low, High: high, Exclusive: e.Exclusive}, nil
+}
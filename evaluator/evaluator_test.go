@@ -0,0 +1,74 @@
+package evaluator_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/evaluator"
+)
+
+func TestLimits(t *testing.T) {
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	args := map[string]any{"a": 1.0}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		expr string
+		opts []evaluator.Option
+		// Error wrapping err, no error for nil
+		err error
+	}{
+		{name: "no limits", expr: `(([a] == 1))`},
+		{name: "deadline", ctx: expired, expr: `[a] == 1`, err: context.DeadlineExceeded},
+		{name: "canceled", ctx: canceled, expr: `[a] == 1`, err: context.Canceled},
+		// The query stops once the context is done
+		{name: "JQ deadline", ctx: expired, expr: `$jq[count](range(1e9)) > 0`, err: context.DeadlineExceeded},
+		// [a] == 1 is a node and its two operands
+		{name: "max nodes", expr: `[a] == 1`, opts: []evaluator.Option{evaluator.WithMaxNodes(3)}},
+		{name: "max nodes exceeded", expr: `[a] == 1`, opts: []evaluator.Option{evaluator.WithMaxNodes(2)}, err: lerrors.ErrLimitExceeded},
+		{name: "max nodes short-circuited", expr: `[a] == 1 OR [a] == 2`, opts: []evaluator.Option{evaluator.WithMaxNodes(4)}},
+		{name: "max nodes of quantifier", expr: `ANY x IN [1, 2, 3] : x == 3`, opts: []evaluator.Option{evaluator.WithMaxNodes(5)}, err: lerrors.ErrLimitExceeded},
+		// The operands of [a] == 1 are at depth 1, in parentheses at depth 2
+		{name: "max depth", expr: `[a] == 1`, opts: []evaluator.Option{evaluator.WithMaxDepth(2)}},
+		{name: "max depth exceeded", expr: `([a] == 1)`, opts: []evaluator.Option{evaluator.WithMaxDepth(2)}, err: lerrors.ErrLimitExceeded},
+		{name: "max depth of arithmetic", expr: `[a] + 1 + 1 + 1 == 4`, opts: []evaluator.Option{evaluator.WithMaxDepth(4)}, err: lerrors.ErrLimitExceeded},
+		{name: "zero is no limit", expr: `((([a] == 1)))`, opts: []evaluator.Option{evaluator.WithMaxDepth(0), evaluator.WithMaxNodes(0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			got, err := evaluator.EvaluateContext(ctx, parse(t, tt.expr), args, tt.opts...)
+			if tt.err == nil {
+				if err != nil || !got {
+					t.Errorf("%v = %v, %v", tt.expr, got, err)
+				}
+				return
+			}
+			if !lerrors.Is(err, tt.err) {
+				t.Errorf("%v: got error %v, want %v", tt.expr, err, tt.err)
+			}
+		})
+	}
+}
+
+// A deadline stops a long JQ query while it runs
+func TestDeadlineDuringJQ(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := evaluator.EvaluateContext(ctx, parse(t, `$jq[count](range(1e12)) > 0`), map[string]any{})
+	if !lerrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("stopped after %v", elapsed)
+	}
+}
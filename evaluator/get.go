@@ -1,8 +1,11 @@
 package evaluator
 
 import (
+	"fmt"
+
 	"github.com/thenam153/conditions-go/ast"
 	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/token"
 )
 
 func getBool(e ast.Expr) (bool, error) {
@@ -49,3 +52,42 @@ func getSliceNumber(e ast.Expr) ([]float64, error) {
 		return nil, lerrors.Newf("Literal is not a slice number: %v", n)
 	}
 }
+
+// Name of the value type of a literal, used in type mismatch errors
+func typeName(e ast.Expr) string {
+	switch e.(type) {
+	case *ast.StringLiteral:
+		return "string"
+	case *ast.NumberLiteral:
+		return "number"
+	case *ast.BooleanLiteral:
+		return "boolean"
+	case *ast.NullLiteral:
+		return "null"
+	case *ast.SliceStringLiteral:
+		return "[]string"
+	case *ast.SliceNumberLiteral:
+		return "[]number"
+	case *ast.ListLiteral:
+		return "array"
+	case *ast.ObjectLiteral:
+		return "object"
+	case *ast.TimeLiteral:
+		return "time"
+	case *ast.DurationLiteral:
+		return "duration"
+	case *ast.VersionLiteral:
+		return "version"
+	case *ast.IPLiteral:
+		return "ip"
+	case *ast.ConstraintLiteral:
+		return "range"
+	case *ast.CIDRLiteral:
+		return "cidr"
+	}
+	return fmt.Sprintf("%T", e)
+}
+
+func mismatch(op token.Token, l, r ast.Expr) error {
+	return lerrors.ErrTypeMismatch{Op: op.String(), Left: typeName(l), Right: typeName(r)}
+}
@@ -0,0 +1,113 @@
+package evaluator
+
+import (
+	"net"
+	"net/netip"
+	"reflect"
+
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/cidr"
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/token"
+)
+
+var (
+	addrType     = reflect.TypeOf(netip.Addr{})
+	netIPType    = reflect.TypeOf(net.IP{})
+	prefixType   = reflect.TypeOf(netip.Prefix{})
+	prefixesType = reflect.TypeOf([]netip.Prefix{})
+)
+
+// Convert IP addresses and prefixes of net and net/netip, ok is false for
+// other values
+func ipLiteral(v reflect.Value) (ast.Expr, bool) {
+	switch v.Type() {
+	case addrType:
+		return &ast.IPLiteral{Value: v.Interface().(netip.Addr)}, true
+	case netIPType:
+		addr, ok := netip.AddrFromSlice(v.Interface().(net.IP))
+		if !ok {
+			return &ast.NullLiteral{}, true
+		}
+		return &ast.IPLiteral{Value: addr.Unmap()}, true
+	case prefixType:
+		return &ast.CIDRLiteral{Value: cidr.NewSet(v.Interface().(netip.Prefix))}, true
+	case prefixesType:
+		return &ast.CIDRLiteral{Value: cidr.NewSet(v.Interface().([]netip.Prefix)...)}, true
+	}
+	return nil, false
+}
+
+// A string compared with an address is parsed as one, strings that are not
+// an address are left for the operator to reject
+func coerceIP(l, r ast.Expr) (ast.Expr, ast.Expr) {
+	parse := func(e ast.Expr) ast.Expr {
+		if s, ok := e.(*ast.StringLiteral); ok {
+			if addr, err := netip.ParseAddr(s.Value); err == nil {
+				return &ast.IPLiteral{Span: s.Span, Value: addr}
+			}
+		}
+		return e
+	}
+	switch {
+	case isIP(l):
+		return l, parse(r)
+	case isIP(r):
+		return parse(l), r
+	}
+	return l, r
+}
+
+func isIP(e ast.Expr) bool {
+	_, ok := e.(*ast.IPLiteral)
+	return ok
+}
+
+// Convert an address or address string, the value of ip()
+func toIP(e ast.Expr) (*ast.IPLiteral, error) {
+	switch v := e.(type) {
+	case *ast.IPLiteral:
+		return v, nil
+	case *ast.StringLiteral:
+		addr, err := netip.ParseAddr(v.Value)
+		if err != nil {
+			return nil, lerrors.Wrap(lerrors.Newf("Cannot convert %q to IP address: %w", v.Value, lerrors.ErrUnsupportedValue), err)
+		}
+		return &ast.IPLiteral{Value: addr}, nil
+	}
+	return nil, lerrors.Newf("Cannot convert %v to IP address: %w", typeName(e), lerrors.ErrUnsupportedValue)
+}
+
+// Apply IN CIDR and NOT IN CIDR. Prefixes of literals are parsed by the
+// parser, prefixes read from arguments on every evaluation.
+func applyCIDR(op token.Token, l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	if _, ok := l.(*ast.StringLiteral); !ok && !isIP(l) {
+		return nil, mismatch(op, l, r)
+	}
+	var set *cidr.Set
+	switch rv := r.(type) {
+	case *ast.CIDRLiteral:
+		set = rv.Value
+	case *ast.StringLiteral, *ast.SliceStringLiteral:
+		var prefixes []string
+		if s, ok := rv.(*ast.StringLiteral); ok {
+			prefixes = []string{s.Value}
+		} else {
+			prefixes = rv.(*ast.SliceStringLiteral).Value
+		}
+		var err error
+		if set, err = cidr.Parse(prefixes...); err != nil {
+			return nil, lerrors.Wrap(lerrors.Newf("Cannot convert RHS of %v to prefixes: %w", op, lerrors.ErrUnsupportedValue), err)
+		}
+	default:
+		if !isEmptySlice(r) {
+			return nil, mismatch(op, l, r)
+		}
+		set = cidr.NewSet()
+	}
+	addr, err := toIP(l)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.BooleanLiteral{Value: set.Contains(addr.Value) == (op == token.INCIDR)}, nil
+}
@@ -0,0 +1,118 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/thenam153/conditions-go/ast"
+	lerrors "github.com/thenam153/conditions-go/errors"
+)
+
+func (ev *evaluation) evaluateJQ(e *ast.JQRef) (ast.Expr, error) {
+	var values []any
+	value, err := ev.document()
+	if err != nil {
+		return nil, err
+	}
+	jqMode, ok := ast.JQModes[e.Mode]
+	if !ok && e.Mode != "" {
+		return nil, lerrors.Newf("Unknown JQ mode %q: %w", e.Mode, lerrors.ErrJQ)
+	}
+	// Queries are compiled by the parser, with the variables it was given
+	if e.Code == nil {
+		return nil, lerrors.Newf("JQ Query %v is not compiled: %w", e.Value, lerrors.ErrJQ)
+	}
+	vars, err := ev.jqVariables(e.Variables)
+	if err != nil {
+		return nil, err
+	}
+	iter := e.Code.RunWithContext(ev.ctx, value, vars...)
+	for {
+		v, n := iter.Next()
+		if !n {
+			break
+		}
+		if err, ok := v.(error); ok {
+			if halted(err) {
+				break
+			}
+			return nil, lerrors.Wrap(lerrors.Newf("JQ Query %v failed: %w", e.Query, lerrors.ErrJQ), err)
+		}
+		if max := ev.opts.maxJQResults; max > 0 && len(values) == max {
+			return nil, lerrors.Newf("JQ Query get more than %d values: %w", max, lerrors.ErrLimitExceeded)
+		}
+		values = append(values, v)
+		// Later values are never used
+		if jqMode == ast.JQFirst {
+			break
+		}
+	}
+	switch jqMode {
+	case ast.JQFirst, ast.JQLast:
+		if len(values) == 0 {
+			return ev.missing(e.Query.String(), lerrors.Newf("JQ Query get no value: %w", lerrors.ErrJQ))
+		}
+		v := values[0]
+		if jqMode == ast.JQLast {
+			v = values[len(values)-1]
+		}
+		if v == nil {
+			return ev.null(e.Query.String())
+		}
+		return jqLiteral(v)
+	case ast.JQArray:
+		if len(values) == 0 {
+			return &ast.SliceStringLiteral{Value: []string{}}, nil
+		}
+		return jqLiteral(values)
+	case ast.JQAny, ast.JQAll:
+		// JQ truthiness: anything but false and null
+		for _, v := range values {
+			if truthy := v != nil && v != false; truthy == (jqMode == ast.JQAny) {
+				return &ast.BooleanLiteral{Value: truthy}, nil
+			}
+		}
+		return &ast.BooleanLiteral{Value: jqMode == ast.JQAll}, nil
+	case ast.JQCount:
+		return &ast.NumberLiteral{Value: float64(len(values))}, nil
+	default:
+		return nil, lerrors.Newf("Not implemented JQMode, JQMode: %v", jqMode)
+	}
+}
+
+// halt ends the output of a query, halt_error fails it
+func halted(err error) bool {
+	h, ok := err.(interface {
+		IsHaltError() bool
+		Value() any
+	})
+	return ok && h.IsHaltError() && h.Value() == nil
+}
+
+// Values of the variables of a JQ query, in order
+func (ev *evaluation) jqVariables(names []string) ([]any, error) {
+	values := make([]any, len(names))
+	for i, name := range names {
+		value, ok := ev.opts.jqVariables[name]
+		if !ok {
+			value, ok = ev.opts.jqVariables[strings.TrimPrefix(name, "$")]
+		}
+		if !ok {
+			return nil, lerrors.Newf("JQ variable %v has no value: %w", name, lerrors.ErrJQ)
+		}
+		value, err := NormalizeJQ(value)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// Convert a JQ result to a literal
+func jqLiteral(v any) (ast.Expr, error) {
+	value, err := toLiteral(v)
+	if err != nil {
+		return nil, lerrors.Wrap(lerrors.Newf("JQ unsupported result %T: %w", v, lerrors.ErrJQ), err)
+	}
+	return value, nil
+}
@@ -0,0 +1,224 @@
+package evaluator_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/gojq"
+	"github.com/thenam153/conditions-go/ast"
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/evaluator"
+	"github.com/thenam153/conditions-go/parser"
+	"github.com/thenam153/conditions-go/token"
+)
+
+// Rule with five JQ references
+const benchmarkRule = `$jq(.user.id) == 1 AND $jq(.user.vip) == "yes" AND $jq(.orders[0].id) == "order-0" AND
+	$jq(.orders | length) > 0 AND $jq(.orders[-1].customer.country) == "VN"`
+
+// Payload of n orders, as decoded from JSON
+func benchmarkArgs(n int) map[string]any {
+	orders := make([]any, n)
+	for i := range orders {
+		orders[i] = map[string]any{
+			"id":       fmt.Sprintf("order-%d", i),
+			"amount":   float64(i),
+			"tags":     []any{"a", "b", "c"},
+			"customer": map[string]any{"name": "customer", "country": "VN"},
+		}
+	}
+	return map[string]any{"orders": orders, "user": map[string]any{"id": 1.0, "vip": "yes"}}
+}
+
+func BenchmarkJQ(b *testing.B) {
+	expr, err := parser.NewParser(strings.NewReader(benchmarkRule)).Parse()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, n := range []int{10, 1000} {
+		args := benchmarkArgs(n)
+		// What every JQ node used to pay before args were normalised once
+		b.Run(fmt.Sprintf("JSONRoundTripPerNode/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := 0; j < 5; j++ {
+					var value any
+					bytes, err := json.Marshal(args)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if err := json.Unmarshal(bytes, &value); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Evaluate/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if ok, err := evaluator.Evaluate(expr, args); err != nil || !ok {
+					b.Fatal(ok, err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("EvaluateJQInput/%d", n), func(b *testing.B) {
+			input, err := evaluator.NormalizeJQ(args)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if ok, err := evaluator.Evaluate(expr, args, evaluator.WithJQInput(input)); err != nil || !ok {
+					b.Fatal(ok, err)
+				}
+			}
+		})
+	}
+}
+
+// halt ends the results of a query, halt_error fails it
+func TestJQHalt(t *testing.T) {
+	args := map[string]any{"xs": []any{1.0, 2.0, 3.0}}
+	tests := []struct {
+		expr string
+		want bool
+		err  bool
+	}{
+		{expr: `$jq[count](.xs[], halt) == 3`, want: true},
+		{expr: `$jq[array](.xs[0], halt, .xs[1]) == [1]`, want: true},
+		{expr: `$jq[any](halt)`, want: false},
+		{expr: `$jq(halt) == 1`, err: true},
+		{expr: `$jq[count](.xs[], halt_error("stop")) == 3`, err: true},
+	}
+	for _, tt := range tests {
+		got, err := evaluator.Evaluate(parse(t, tt.expr), args)
+		if (err != nil) != tt.err {
+			t.Errorf("%v: got error %v, want error %v", tt.expr, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+// Queries the parser did not compile are rejected, not run without variables
+func TestJQUncompiled(t *testing.T) {
+	query, err := gojq.Parse(".a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expr := &ast.BinaryExpr{
+		OP:  token.EQ,
+		LHS: &ast.JQRef{Value: ".a", Query: query},
+		RHS: &ast.NumberLiteral{Value: 1},
+	}
+	if _, err := evaluator.Evaluate(expr, map[string]any{"a": 1}); !lerrors.Is(err, lerrors.ErrJQ) {
+		t.Errorf("got error %v, want ErrJQ", err)
+	}
+}
+
+func TestJQResults(t *testing.T) {
+	args := map[string]any{
+		"xs":    []any{1.0, 2.0, 3.0},
+		"flags": []any{true, false, nil},
+		"mixed": []any{1.0, "a", true, nil},
+		"obj":   map[string]any{"k": 1.0, "tags": []any{"x", "y"}},
+		"t":     true,
+		"n":     nil,
+	}
+	tests := []struct {
+		expr string
+		want bool
+		// Error wrapping err, or any error for errors.ErrJQ
+		err error
+	}{
+		// Modes
+		{expr: `$jq(.xs[]) == 1`, want: true},
+		{expr: `$jq[first](.xs[]) == 1`, want: true},
+		{expr: `$jq[last](.xs[]) == 3`, want: true},
+		{expr: `$jq[array](.xs[]) == [1, 2, 3]`, want: true},
+		{expr: `$jq[array](.xs[] | select(. > 5)) == []`, want: true},
+		{expr: `$jq[count](.xs[]) == 3`, want: true},
+		{expr: `$jq[count](.xs[] | select(. > 5)) == 0`, want: true},
+		{expr: `$jq[any](.xs[] > 2)`, want: true},
+		{expr: `$jq[any](.xs[] > 3)`, want: false},
+		{expr: `$jq[any](empty)`, want: false},
+		{expr: `$jq[all](.xs[] > 0)`, want: true},
+		{expr: `$jq[all](.xs[] > 1)`, want: false},
+		{expr: `$jq[all](empty)`, want: true},
+		// JQ truthiness, anything but false and null
+		{expr: `$jq[any](.flags[])`, want: true},
+		{expr: `$jq[all](.flags[])`, want: false},
+		{expr: `$jq[all](.xs[], "", 0)`, want: true},
+		{expr: `$jq[any](.flags[1], .flags[2])`, want: false},
+		{expr: `$jq[first](.xs[] | select(. > 5)) == 1`, err: lerrors.ErrJQ},
+		// Result conversions
+		{expr: `$jq(.t)`, want: true},
+		{expr: `$jq(.t) == true`, want: true},
+		{expr: `$jq(.n) IS NULL`, want: true},
+		{expr: `$jq(.missing) IS NULL`, want: true},
+		{expr: `$jq(.xs) == [1, 2, 3]`, want: true},
+		{expr: `$jq(.obj.tags) == ["x", "y"]`, want: true},
+		{expr: `$jq(.obj) == $jq(.obj)`, want: true},
+		{expr: `$jq(.obj.k) == 1`, want: true},
+		{expr: `$jq(.obj | keys) == ["k", "tags"]`, want: true},
+		{expr: `$jq(.obj)`, err: errAny},
+		{expr: `$jq(.obj) == 1`, err: errAny},
+		// Mixed-type arrays are lists, compared by element
+		{expr: `$jq(.mixed) == [1, "a", true, NULL]`, want: true},
+		{expr: `"a" IN $jq(.mixed)`, want: true},
+		{expr: `$jq(.mixed) INTERSECTS ["a", 2]`, want: true},
+		{expr: `$jq(.mixed) == ["a"]`, want: false},
+		{expr: `$jq(.mixed) > 1`, err: errAny},
+		{expr: `$jq(.mixed) STARTS WITH "a"`, err: errAny},
+		{expr: `$jq(.mixed) + 1 > 1`, err: errAny},
+		// Errors of the query
+		{expr: `$jq(.xs | error("boom")) == 1`, err: lerrors.ErrJQ},
+		{expr: `$jq(.t | keys) == 1`, err: lerrors.ErrJQ},
+	}
+	for _, tt := range tests {
+		got, err := evaluator.Evaluate(parse(t, tt.expr), args)
+		switch {
+		case tt.err == nil && err != nil:
+			t.Errorf("%v: %v", tt.expr, err)
+		case tt.err != nil && (err == nil || tt.err != errAny && !lerrors.Is(err, tt.err)):
+			t.Errorf("%v: got error %v, want %v", tt.expr, err, tt.err)
+		case got != tt.want:
+			t.Errorf("%v = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+// Any error, for tests of errors with no sentinel
+var errAny = lerrors.New("any error")
+
+func TestJQMaxResults(t *testing.T) {
+	args := map[string]any{"xs": []any{1.0, 2.0, 3.0}}
+	tests := []struct {
+		expr string
+		max  int
+		err  bool
+	}{
+		{expr: `$jq[count](.xs[]) == 3`, max: 3},
+		{expr: `$jq[count](.xs[]) == 3`, max: 2, err: true},
+		{expr: `$jq[array](.xs[]) == [1, 2, 3]`, max: 2, err: true},
+		{expr: `$jq[count](.xs[]) == 3`},
+		// The first value ends the query
+		{expr: `$jq(.xs[]) == 1`, max: 1},
+		{expr: `$jq(range(1; infinite)) == 1`, max: 1},
+		{expr: `$jq[any](range(1; 100) > 50)`, max: 10, err: true},
+	}
+	for _, tt := range tests {
+		got, err := evaluator.Evaluate(parse(t, tt.expr), args, evaluator.WithMaxJQResults(tt.max))
+		if tt.err {
+			if !lerrors.Is(err, lerrors.ErrLimitExceeded) {
+				t.Errorf("%v with at most %d results: got error %v, want ErrLimitExceeded", tt.expr, tt.max, err)
+			}
+			continue
+		}
+		if err != nil || !got {
+			t.Errorf("%v with at most %d results = %v, %v", tt.expr, tt.max, got, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/token"
+)
+
+// Keys of the elements of a list, equal elements have equal keys
+type (
+	timeKey struct {
+		sec  int64
+		nsec int
+	}
+	versionKey string
+	listKey    string
+	objectKey  string
+)
+
+// Comparable key of an array element. Strings and numbers are keyed by
+// their value as in slice literals, nested arrays by their set of keys and
+// objects by their JSON encoding.
+func elementKey(e ast.Expr) (any, bool) {
+	switch v := e.(type) {
+	case *ast.StringLiteral:
+		return v.Value, true
+	case *ast.NumberLiteral:
+		return v.Value, true
+	case *ast.BooleanLiteral:
+		return v.Value, true
+	case *ast.NullLiteral:
+		return nil, true
+	case *ast.TimeLiteral:
+		return timeKey{v.Value.Unix(), v.Value.Nanosecond()}, true
+	case *ast.DurationLiteral:
+		return v.Value, true
+	case *ast.VersionLiteral:
+		version := v.Value
+		version.Build = ""
+		return versionKey(version.String()), true
+	case *ast.IPLiteral:
+		return v.Value, true
+	case *ast.SliceStringLiteral, *ast.SliceNumberLiteral, *ast.ListLiteral:
+		set, ok := toSet(e)
+		if !ok {
+			return nil, false
+		}
+		keys := make([]string, 0, len(set))
+		for k := range set {
+			keys = append(keys, fmt.Sprintf("%T:%v", k, k))
+		}
+		sort.Strings(keys)
+		return listKey(strings.Join(keys, ",")), true
+	case *ast.ObjectLiteral:
+		// Map keys are encoded sorted
+		bytes, err := json.Marshal(v.Value)
+		if err != nil {
+			return nil, false
+		}
+		return objectKey(bytes), true
+	}
+	return nil, false
+}
+
+// Report whether the list holds an element equal to l, elements of another
+// type are not equal
+func listContains(l ast.Expr, list *ast.ListLiteral) bool {
+	for _, elem := range list.Value {
+		if isNull(elem) {
+			continue
+		}
+		lv, rv := coerce(l, elem)
+		if equal, err := compareEQ(token.EQ, lv, rv); err == nil && equal {
+			return true
+		}
+	}
+	return false
+}
+
+// Value of a literal as an argument, e.g. for the elements a quantifier
+// binds
+func literalValue(e ast.Expr) any {
+	switch v := e.(type) {
+	case *ast.StringLiteral:
+		return v.Value
+	case *ast.NumberLiteral:
+		return v.Value
+	case *ast.BooleanLiteral:
+		return v.Value
+	case *ast.TimeLiteral:
+		return v.Value
+	case *ast.DurationLiteral:
+		return v.Value
+	case *ast.VersionLiteral:
+		return v.Value
+	case *ast.IPLiteral:
+		return v.Value
+	case *ast.SliceStringLiteral:
+		return v.Value
+	case *ast.SliceNumberLiteral:
+		return v.Value
+	case *ast.ObjectLiteral:
+		return v.Value
+	case *ast.ListLiteral:
+		values := make([]any, len(v.Value))
+		for i, elem := range v.Value {
+			values[i] = literalValue(elem)
+		}
+		return values
+	}
+	return nil
+}
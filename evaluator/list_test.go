@@ -0,0 +1,87 @@
+package evaluator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thenam153/conditions-go/evaluator"
+	"github.com/thenam153/conditions-go/parser"
+)
+
+func TestListLiterals(t *testing.T) {
+	args := map[string]any{
+		"n":      1.0,
+		"s":      "a",
+		"b":      true,
+		"pairs":  []any{[]any{1.0, 2.0}, []any{"a"}},
+		"mixed":  []any{1.0, "a", nil},
+		"nested": []any{[]any{1.0, []any{2.0}}},
+	}
+	tests := []struct {
+		expr string
+		// Rejected by the parser with WithStrictArrays
+		mixed bool
+		want  string
+	}{
+		// Empty
+		{expr: `[] == []`, want: "TRUE"},
+		{expr: `[n] IN []`, want: "FALSE"},
+		{expr: `[s] NOT IN []`, want: "TRUE"},
+		{expr: `[] SUBSET OF [1]`, want: "TRUE"},
+		// Mixed
+		{expr: `[n] IN [1, "a"]`, mixed: true, want: "TRUE"},
+		{expr: `[s] IN [1, "a"]`, mixed: true, want: "TRUE"},
+		{expr: `"1" IN [1, "a"]`, mixed: true, want: "FALSE"},
+		{expr: `[b] IN [1, TRUE]`, mixed: true, want: "TRUE"},
+		{expr: `[mixed] == [1, "a", NULL]`, mixed: true, want: "TRUE"},
+		{expr: `[mixed] == ["a", NULL, 1]`, mixed: true, want: "TRUE"},
+		{expr: `[mixed] == [1, "a"]`, mixed: true, want: "FALSE"},
+		{expr: `[mixed] CONTAINS 1`, want: "TRUE"},
+		{expr: `[mixed] CONTAINS "1"`, want: "FALSE"},
+		{expr: `[n] IN [1, 2d]`, mixed: true, want: "TRUE"},
+		// NULL fits any array
+		{expr: `[n] IN [1, NULL]`, want: "TRUE"},
+		{expr: `[s] IN ["b", NULL]`, want: "FALSE"},
+		// Nested
+		{expr: `[pairs] == [[1, 2], ["a"]]`, want: "TRUE"},
+		{expr: `[pairs] == [["a"], [2, 1]]`, want: "TRUE"},
+		{expr: `[1, 2] IN [pairs]`, want: "TRUE"},
+		{expr: `["b"] IN [pairs]`, want: "FALSE"},
+		{expr: `[nested] == [[1, [2]]]`, mixed: true, want: "TRUE"},
+		{expr: `[nested] == [[1, [3]]]`, mixed: true, want: "FALSE"},
+		{expr: `[pairs] INTERSECTS [["a"], [3]]`, want: "TRUE"},
+		{expr: `[n] IN [[1], 1]`, mixed: true, want: "TRUE"},
+		// Lists have no order
+		{expr: `[1, "a"] < [2]`, mixed: true, want: "error"},
+	}
+	for _, tt := range tests {
+		for _, strict := range []bool{false, true} {
+			var opts []parser.Option
+			if strict {
+				opts = append(opts, parser.WithStrictArrays())
+			}
+			expr, err := parser.NewParser(strings.NewReader(tt.expr), opts...).Parse()
+			if strict && tt.mixed {
+				if err == nil {
+					t.Errorf("%v: parsed with strict arrays", tt.expr)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%v, strict arrays %v: %v", tt.expr, strict, err)
+				continue
+			}
+			got, err := evaluator.Evaluate(expr, args)
+			outcome := "FALSE"
+			switch {
+			case err != nil:
+				outcome = "error"
+			case got:
+				outcome = "TRUE"
+			}
+			if outcome != tt.want {
+				t.Errorf("%v, strict arrays %v = %v (%v), want %v", tt.expr, strict, outcome, err, tt.want)
+			}
+		}
+	}
+}
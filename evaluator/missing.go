@@ -0,0 +1,152 @@
+package evaluator
+
+import (
+	"github.com/thenam153/conditions-go/ast"
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/token"
+)
+
+// Missing is the evaluation of an argument absent from args, or a JQ query
+// without result
+type Missing int
+
+const (
+	// MissingError fails the evaluation
+	MissingError Missing = iota
+	// MissingNull evaluates to NULL with SQL-like three-valued logic:
+	// comparisons with NULL are unknown, FALSE AND NULL is FALSE, TRUE OR
+	// NULL is TRUE, an unknown result does not match.
+	MissingNull
+	// MissingFalse evaluates every comparison with a missing operand to FALSE
+	MissingFalse
+	// MissingDefault substitutes the default given with WithDefaults
+	MissingDefault
+)
+
+func (ev *evaluation) missing(key string, err error) (ast.Expr, error) {
+	if ev.nullable && ev.opts.missing != MissingDefault {
+		return &ast.NullLiteral{}, nil
+	}
+	switch ev.opts.missing {
+	case MissingNull, MissingFalse:
+		return &ast.NullLiteral{}, nil
+	case MissingDefault:
+		if value, ok := ev.opts.defaults[key]; ok {
+			return toLiteral(value)
+		}
+	}
+	if ev.nullable {
+		return &ast.NullLiteral{}, nil
+	}
+	return nil, err
+}
+
+// A null value found at key, only replaced by a default
+func (ev *evaluation) null(key string) (ast.Expr, error) {
+	if ev.opts.missing == MissingDefault {
+		if value, ok := ev.opts.defaults[key]; ok {
+			return toLiteral(value)
+		}
+	}
+	return &ast.NullLiteral{}, nil
+}
+
+func (ev *evaluation) evaluateUnary(e *ast.UnaryExpr) (ast.Expr, error) {
+	switch e.OP {
+	case token.EXISTS:
+		ref, ok := e.Expr.(*ast.VarRef)
+		if !ok {
+			return nil, lerrors.Newf("EXISTS operand must be an argument, got: %T", e.Expr)
+		}
+		_, ok, err := ev.lookup(ref.Value)
+		if err != nil {
+			return nil, lerrors.NewWrap("Cannot resolve args with index "+ref.Value, err)
+		}
+		return &ast.BooleanLiteral{Value: ok}, nil
+	case token.ISNULL, token.ISNOTNULL:
+		return ev.evaluateIsNull(e.Expr, e.OP == token.ISNULL)
+	}
+	return nil, lerrors.Newf("Not implemented operator, Op: %v", e.OP.String())
+}
+
+func (ev *evaluation) evaluateIsNull(expr ast.Expr, want bool) (ast.Expr, error) {
+	nullable := ev.nullable
+	ev.nullable = true
+	value, err := ev.evaluateTree(expr)
+	ev.nullable = nullable
+	if err != nil {
+		return nil, lerrors.NewWrap("Cannot evaluate operand of IS NULL", err)
+	}
+	return &ast.BooleanLiteral{Value: isNull(value) == want}, nil
+}
+
+// Return the operand compared with a NULL literal by == or !=
+func nullComparison(e *ast.BinaryExpr) (ast.Expr, bool) {
+	if e.OP != token.EQ && e.OP != token.NEQ {
+		return nil, false
+	}
+	if isNull(e.RHS) {
+		return e.LHS, true
+	}
+	if isNull(e.LHS) {
+		return e.RHS, true
+	}
+	return nil, false
+}
+
+func isNull(e ast.Expr) bool {
+	_, ok := e.(*ast.NullLiteral)
+	return ok
+}
+
+// Apply operator with at least one NULL operand. Arithmetic gives NULL,
+// comparison, regex and membership operators are unknown (FALSE with
+// MissingFalse), logical operators follow three-valued logic.
+func (ev *evaluation) applyNull(op token.Token, l, r ast.Expr) (ast.Expr, error) {
+	if op.IsArithmetic() {
+		return &ast.NullLiteral{}, nil
+	}
+	if !op.IsLogical() {
+		if ev.opts.missing == MissingFalse {
+			return &ast.BooleanLiteral{Value: false}, nil
+		}
+		return &ast.NullLiteral{}, nil
+	}
+	// Logical operand is either a boolean or unknown
+	value := func(e ast.Expr) (bool, bool, error) {
+		if isNull(e) {
+			return false, ev.opts.missing != MissingFalse, nil
+		}
+		v, err := getBool(e)
+		if err != nil {
+			return false, false, mismatch(op, l, r)
+		}
+		return v, false, nil
+	}
+	lv, lnull, err := value(l)
+	if err != nil {
+		return nil, err
+	}
+	rv, rnull, err := value(r)
+	if err != nil {
+		return nil, err
+	}
+	if !lnull && !rnull {
+		return applyOperator(op, &ast.BooleanLiteral{Value: lv}, &ast.BooleanLiteral{Value: rv})
+	}
+	switch op {
+	case token.AND, token.NAND:
+		// A FALSE operand decides the result
+		if (!lnull && !lv) || (!rnull && !rv) {
+			return &ast.BooleanLiteral{Value: op == token.NAND}, nil
+		}
+	case token.OR:
+		if (!lnull && lv) || (!rnull && rv) {
+			return &ast.BooleanLiteral{Value: true}, nil
+		}
+	case token.XOR:
+	default:
+		return nil, lerrors.Newf("Not implemented operator, Op: %v", op.String())
+	}
+	return &ast.NullLiteral{}, nil
+}
@@ -0,0 +1,176 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/thenam153/conditions-go/evaluator"
+)
+
+// Outcome of expr: TRUE, FALSE, NULL for an unknown result, or error
+func outcome(t *testing.T, expr string, args map[string]any, opts ...evaluator.Option) string {
+	t.Helper()
+	got, err := evaluator.Evaluate(parse(t, expr), args, opts...)
+	switch {
+	case err != nil:
+		return "error"
+	case got:
+		return "TRUE"
+	}
+	// The root of an unknown result does not match, IS NULL tells it apart
+	null, err := evaluator.Evaluate(parse(t, "("+expr+") IS NULL"), args, opts...)
+	switch {
+	case err != nil:
+		t.Fatalf("(%v) IS NULL: %v", expr, err)
+	case null:
+		return "NULL"
+	}
+	return "FALSE"
+}
+
+// Kleene logic of the language, NULL is unknown
+func kleene(op, l, r string) string {
+	switch op {
+	case "AND", "NAND":
+		v := "TRUE"
+		if l == "FALSE" || r == "FALSE" {
+			v = "FALSE"
+		} else if l == "NULL" || r == "NULL" {
+			v = "NULL"
+		}
+		if op == "NAND" {
+			v = map[string]string{"TRUE": "FALSE", "FALSE": "TRUE", "NULL": "NULL"}[v]
+		}
+		return v
+	case "OR":
+		if l == "TRUE" || r == "TRUE" {
+			return "TRUE"
+		}
+		if l == "NULL" || r == "NULL" {
+			return "NULL"
+		}
+		return "FALSE"
+	case "XOR":
+		if l == "NULL" || r == "NULL" {
+			return "NULL"
+		}
+		if l != r {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	panic(op)
+}
+
+func TestThreeValuedLogic(t *testing.T) {
+	args := map[string]any{"t": true, "f": false, "z": nil}
+	tests := []struct {
+		name string
+		// Operands of each value, NULL from a null argument or a missing one
+		operands map[string]string
+		opts     []evaluator.Option
+		// Value NULL operands take in logical operators
+		null string
+	}{
+		{name: "null argument", operands: map[string]string{"TRUE": "[t]", "FALSE": "[f]", "NULL": "[z]"}, null: "NULL"},
+		{
+			name:     "MissingNull",
+			operands: map[string]string{"TRUE": "[t]", "FALSE": "[f]", "NULL": "[n]"},
+			opts:     []evaluator.Option{evaluator.WithMissing(evaluator.MissingNull)},
+			null:     "NULL",
+		},
+		{
+			name:     "MissingFalse",
+			operands: map[string]string{"TRUE": "[t]", "FALSE": "[f]", "NULL": "[n]"},
+			opts:     []evaluator.Option{evaluator.WithMissing(evaluator.MissingFalse)},
+			null:     "FALSE",
+		},
+	}
+	values := []string{"TRUE", "FALSE", "NULL"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := func(v string) string {
+				if v == "NULL" {
+					return tt.null
+				}
+				return v
+			}
+			for _, op := range []string{"AND", "OR", "NAND", "XOR"} {
+				for _, l := range values {
+					for _, r := range values {
+						expr := tt.operands[l] + " " + op + " " + tt.operands[r]
+						if got, want := outcome(t, expr, args, tt.opts...), kleene(op, value(l), value(r)); got != want {
+							t.Errorf("%v = %v, want %v", expr, got, want)
+						}
+					}
+				}
+			}
+			// The language has no NOT, XOR TRUE negates
+			for _, v := range values {
+				expr := tt.operands[v] + " XOR TRUE"
+				if got, want := outcome(t, expr, args, tt.opts...), kleene("XOR", value(v), "TRUE"); got != want {
+					t.Errorf("%v = %v, want %v", expr, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMissing(t *testing.T) {
+	args := map[string]any{"t": true, "z": nil, "obj": map[string]any{"k": nil}}
+	modes := []struct {
+		name string
+		opts []evaluator.Option
+	}{
+		{name: "MissingError"},
+		{name: "MissingNull", opts: []evaluator.Option{evaluator.WithMissing(evaluator.MissingNull)}},
+		{name: "MissingFalse", opts: []evaluator.Option{evaluator.WithMissing(evaluator.MissingFalse)}},
+		{name: "MissingDefault", opts: []evaluator.Option{evaluator.WithDefaults(map[string]any{"n": 5.0, ".obj.k": 3.0})}},
+	}
+	tests := []struct {
+		expr string
+		// Outcome by mode, in the order of modes
+		want [4]string
+	}{
+		// Comparisons with a missing argument
+		{`[n] == 5`, [4]string{"error", "NULL", "FALSE", "TRUE"}},
+		{`[n] != 5`, [4]string{"error", "NULL", "FALSE", "FALSE"}},
+		{`[n] > 1`, [4]string{"error", "NULL", "FALSE", "TRUE"}},
+		{`[n] + 1 == 6`, [4]string{"error", "NULL", "FALSE", "TRUE"}},
+		{`[n] IN [5]`, [4]string{"error", "NULL", "FALSE", "TRUE"}},
+		{`[n] CONTAINS "5"`, [4]string{"error", "NULL", "FALSE", "error"}},
+		// Without a default
+		{`[m] == 1`, [4]string{"error", "NULL", "FALSE", "error"}},
+		// Comparisons with a null argument are unknown in any mode
+		{`[z] == 1`, [4]string{"NULL", "NULL", "FALSE", "NULL"}},
+		{`[z] != 1`, [4]string{"NULL", "NULL", "FALSE", "NULL"}},
+		{`[z] + 1 > 0`, [4]string{"NULL", "NULL", "FALSE", "NULL"}},
+		// IS NULL and == NULL see a missing argument as NULL, unless defaulted
+		{`[n] IS NULL`, [4]string{"TRUE", "TRUE", "TRUE", "FALSE"}},
+		{`[n] IS NOT NULL`, [4]string{"FALSE", "FALSE", "FALSE", "TRUE"}},
+		{`[n] == NULL`, [4]string{"TRUE", "TRUE", "TRUE", "FALSE"}},
+		{`NULL != [n]`, [4]string{"FALSE", "FALSE", "FALSE", "TRUE"}},
+		{`[z] IS NULL`, [4]string{"TRUE", "TRUE", "TRUE", "TRUE"}},
+		{`[z] IS NOT NULL`, [4]string{"FALSE", "FALSE", "FALSE", "FALSE"}},
+		// EXISTS ignores the mode and defaults, a null argument exists
+		{`EXISTS [n]`, [4]string{"FALSE", "FALSE", "FALSE", "FALSE"}},
+		{`EXISTS [z]`, [4]string{"TRUE", "TRUE", "TRUE", "TRUE"}},
+		{`EXISTS [obj][k]`, [4]string{"TRUE", "TRUE", "TRUE", "TRUE"}},
+		{`EXISTS [obj][x]`, [4]string{"FALSE", "FALSE", "FALSE", "FALSE"}},
+		// A short-circuited RHS is never looked up
+		{`[t] OR [m] == 1`, [4]string{"TRUE", "TRUE", "TRUE", "TRUE"}},
+		{`[m] == 1 OR [t]`, [4]string{"error", "TRUE", "TRUE", "error"}},
+		{`[n] == 1 AND [t]`, [4]string{"error", "NULL", "FALSE", "FALSE"}},
+		// JQ: a null result is NULL, no result is missing
+		{`$jq(.obj.k) == 3`, [4]string{"NULL", "NULL", "FALSE", "TRUE"}},
+		{`$jq(.obj.x) == 3`, [4]string{"NULL", "NULL", "FALSE", "NULL"}},
+		{`$jq(.obj[]?, empty) == 3`, [4]string{"NULL", "NULL", "FALSE", "NULL"}},
+		{`$jq(empty) == 3`, [4]string{"error", "NULL", "FALSE", "error"}},
+	}
+	for _, tt := range tests {
+		for i, mode := range modes {
+			if got := outcome(t, tt.expr, args, mode.opts...); got != tt.want[i] {
+				t.Errorf("%v with %v = %v, want %v", tt.expr, mode.name, got, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package evaluator
+
+import (
+	"encoding"
+	"encoding/json"
+	"math"
+	"reflect"
+
+	lerrors "github.com/thenam153/conditions-go/errors"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// NormalizeJQ converts v to a value JQ queries run against: nil, bool, int,
+// float64, string, []any and map[string]any. The result is what a JSON round
+// trip gives, except integers are kept exact and structs are keyed like
+// [field] args: by the cond tag, then the json tag, then the field name.
+// Normalising args once and passing the result with WithJQInput saves the
+// conversion on every evaluation.
+func NormalizeJQ(v any) (any, error) {
+	return normalize(reflect.ValueOf(v))
+}
+
+func normalize(v reflect.Value) (any, error) {
+	// Fast path for the values json.Unmarshal produces
+	if v.IsValid() && v.Kind() != reflect.Pointer && v.Kind() != reflect.Interface {
+		switch value := v.Interface().(type) {
+		case string, bool, float64, int:
+			return value, nil
+		}
+	}
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil, nil
+	}
+	t := v.Type()
+	if t == jsonNumberType || t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return normalizeJSON(v)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n := v.Uint(); n <= math.MaxInt {
+			return int(n), nil
+		}
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice:
+		// []byte is a base64 string in JSON
+		if v.IsNil() || t.Elem().Kind() == reflect.Uint8 {
+			return normalizeJSON(v)
+		}
+		return normalizeSlice(v)
+	case reflect.Array:
+		return normalizeSlice(v)
+	case reflect.Map:
+		if v.IsNil() || t.Key().Kind() != reflect.String {
+			return normalizeJSON(v)
+		}
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value, err := normalize(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			m[iter.Key().String()] = value
+		}
+		return m, nil
+	case reflect.Struct:
+		return normalizeStruct(v)
+	}
+	// Anything else follows encoding/json rules
+	return normalizeJSON(v)
+}
+
+// Fields of a struct keyed by argument name, so $jq(.name) sees what [name] does
+func normalizeStruct(v reflect.Value) (any, error) {
+	fields := structFields(v.Type())
+	m := make(map[string]any, len(fields))
+	for name, index := range fields {
+		field := fieldByIndex(v, index)
+		if !field.IsValid() {
+			// Promoted through a nil embedded pointer
+			continue
+		}
+		value, err := normalize(field)
+		if err != nil {
+			return nil, err
+		}
+		m[name] = value
+	}
+	return m, nil
+}
+
+func normalizeSlice(v reflect.Value) (any, error) {
+	values := make([]any, v.Len())
+	for i := range values {
+		value, err := normalize(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func normalizeJSON(v reflect.Value) (any, error) {
+	var (
+		bytes []byte
+		err   error
+		value any
+	)
+	if !v.CanInterface() {
+		return nil, nil
+	}
+	if bytes, err = json.Marshal(v.Interface()); err != nil {
+		return nil, lerrors.Wrap(lerrors.Newf("Cannot marshal %v to JSON: %w", v.Type(), lerrors.ErrJQ), err)
+	}
+	if err = json.Unmarshal(bytes, &value); err != nil {
+		return nil, lerrors.Wrap(lerrors.Newf("Cannot unmarshal %v to any: %w", v.Type(), lerrors.ErrJQ), err)
+	}
+	return value, nil
+}
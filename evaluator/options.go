@@ -0,0 +1,135 @@
+package evaluator
+
+import (
+	"time"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+type Option func(*options)
+
+type options struct {
+	trace        *Trace
+	coverage     *Coverage
+	missing      Missing
+	defaults     map[string]any
+	maxJQResults int
+	maxDepth     int
+	maxNodes     int
+	jqInput      *any
+	jqVariables  map[string]any
+	caseFolding  bool
+	normalize    *norm.Form
+	collation    *collation
+	clock        func() time.Time
+}
+
+type collation struct {
+	tag  language.Tag
+	opts []collate.Option
+}
+
+// WithTrace records every evaluated node and its result into t
+func WithTrace(t *Trace) Option {
+	return func(o *options) {
+		o.trace = t
+	}
+}
+
+// WithCoverage records outcome counts of every evaluated node into c
+func WithCoverage(c *Coverage) Option {
+	return func(o *options) {
+		o.coverage = c
+	}
+}
+
+// WithMissing sets how missing arguments and empty JQ results are evaluated
+func WithMissing(m Missing) Option {
+	return func(o *options) {
+		o.missing = m
+	}
+}
+
+// WithDefaults substitutes missing arguments with defaults, keyed by
+// argument name or by JQ query. Arguments without default are an error.
+func WithDefaults(defaults map[string]any) Option {
+	return func(o *options) {
+		o.missing = MissingDefault
+		o.defaults = defaults
+	}
+}
+
+// WithMaxJQResults fails the evaluation when a JQ query yields more than n
+// results, 0 means no limit
+func WithMaxJQResults(n int) Option {
+	return func(o *options) {
+		o.maxJQResults = n
+	}
+}
+
+// WithMaxDepth fails the evaluation of expressions nested deeper than n, 0
+// means no limit
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithMaxNodes fails the evaluation after n evaluated nodes, 0 means no limit
+func WithMaxNodes(n int) Option {
+	return func(o *options) {
+		o.maxNodes = n
+	}
+}
+
+// WithJQInput runs JQ queries against v instead of args, v must already be
+// normalised by NormalizeJQ
+func WithJQInput(v any) Option {
+	return func(o *options) {
+		o.jqInput = &v
+	}
+}
+
+// WithJQVariables gives the values of the variables declared to the parser
+// with parser.WithJQVariables, keyed by name with or without the leading $
+func WithJQVariables(vars map[string]any) Option {
+	return func(o *options) {
+		o.jqVariables = vars
+	}
+}
+
+// WithCaseFolding compares strings of ==, !=, IN, NOT IN and the set
+// operators case-insensitively, array elements included, with full Unicode
+// case folding: "Straße" == "STRASSE"
+func WithCaseFolding() Option {
+	return func(o *options) {
+		o.caseFolding = true
+	}
+}
+
+// WithNormalization compares strings of ==, !=, IN, NOT IN and the set
+// operators in the Unicode normalisation form f, e.g. norm.NFC, array elements
+// included
+func WithNormalization(f norm.Form) Option {
+	return func(o *options) {
+		o.normalize = &f
+	}
+}
+
+// WithCollation orders strings of <, <=, > and >= with the collation rules of
+// tag instead of byte-wise, e.g. language.German with collate.IgnoreCase
+func WithCollation(tag language.Tag, opts ...collate.Option) Option {
+	return func(o *options) {
+		o.collation = &collation{tag: tag, opts: opts}
+	}
+}
+
+// WithClock sets the clock of now(), it is read once per evaluation.
+// Defaults to time.Now.
+func WithClock(clock func() time.Time) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
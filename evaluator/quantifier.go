@@ -0,0 +1,138 @@
+package evaluator
+
+import (
+	"reflect"
+
+	"github.com/thenam153/conditions-go/ast"
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/token"
+)
+
+// Evaluate ANY, ALL and NONE with three-valued logic: ANY is TRUE when the
+// condition is TRUE for an element, NULL when it is NULL for one and never
+// TRUE, FALSE otherwise. ALL is the dual, NONE is NOT ANY.
+func (ev *evaluation) evaluateQuantifier(e *ast.QuantifierExpr) (ast.Expr, error) {
+	elements, ok, err := ev.elements(e.Source)
+	if err != nil || !ok {
+		return &ast.NullLiteral{}, err
+	}
+	// TRUE decides ANY and NONE, FALSE decides ALL
+	decisive := e.OP != token.ALL
+	null := false
+	if ev.bound == nil {
+		ev.bound = map[string]any{}
+	}
+	outer, shadowed := ev.bound[e.Var]
+	defer func() {
+		if shadowed {
+			ev.bound[e.Var] = outer
+		} else {
+			delete(ev.bound, e.Var)
+		}
+	}()
+	for _, element := range elements {
+		ev.bound[e.Var] = element
+		result, err := ev.evaluateTree(e.Body)
+		if err != nil {
+			return nil, lerrors.NewWrap("Cannot evaluate condition of "+e.OP.String(), err)
+		}
+		if isNull(result) {
+			null = true
+			continue
+		}
+		v, err := getBool(result)
+		if err != nil {
+			return nil, lerrors.Newf("Condition of %v must be boolean, got %v", e.OP, typeName(result))
+		}
+		if v == decisive {
+			return &ast.BooleanLiteral{Value: e.OP == token.ANY}, nil
+		}
+	}
+	if null {
+		return &ast.NullLiteral{}, nil
+	}
+	return &ast.BooleanLiteral{Value: e.OP != token.ANY}, nil
+}
+
+// Elements of the list a quantifier ranges over, ok is false for a NULL or
+// missing list. Lists of arguments may hold any value, e.g. maps.
+func (ev *evaluation) elements(e ast.Expr) ([]any, bool, error) {
+	var (
+		value any
+		ok    bool
+		err   error
+		name  string
+	)
+	switch ref := e.(type) {
+	case *ast.VarRef:
+		name = ref.Value
+		if value, ok, err = ev.lookup(name); err != nil {
+			return nil, false, lerrors.NewWrap("Cannot resolve args with index "+name, err)
+		}
+	case *ast.BoundRef:
+		name = ast.String(ref)
+		value, ok = ev.bound[ref.Name], true
+		if ref.Path != "" {
+			value, ok = lookup(value, ref.Path)
+		}
+	default:
+		list, err := ev.evaluateTree(e)
+		if err != nil {
+			return nil, false, lerrors.NewWrap("Cannot evaluate list of quantifier", err)
+		}
+		switch v := list.(type) {
+		case *ast.NullLiteral:
+			return nil, false, nil
+		case *ast.SliceStringLiteral:
+			elements := make([]any, len(v.Value))
+			for i, s := range v.Value {
+				elements[i] = s
+			}
+			return elements, true, nil
+		case *ast.SliceNumberLiteral:
+			elements := make([]any, len(v.Value))
+			for i, n := range v.Value {
+				elements[i] = n
+			}
+			return elements, true, nil
+		case *ast.ListLiteral:
+			return literalValue(v).([]any), true, nil
+		}
+		return nil, false, lerrors.Newf("Quantifier list must be an array, got %v", typeName(list))
+	}
+	if !ok {
+		if ev.opts.missing == MissingDefault {
+			value, ok = ev.opts.defaults[name]
+		}
+		if !ok {
+			_, err := ev.missing(name, lerrors.Newf("Cannot get args with index %v: %w", name, lerrors.ErrUnknownVariable))
+			return nil, false, err
+		}
+	}
+	v := indirect(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return nil, false, nil
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false, lerrors.Newf("Quantifier list %v must be an array, got %T: %w", name, value, lerrors.ErrUnsupportedValue)
+	}
+	elements := make([]any, v.Len())
+	for i := range elements {
+		elements[i] = v.Index(i).Interface()
+	}
+	return elements, true, nil
+}
+
+// Evaluate a reference to the element bound by a quantifier
+func (ev *evaluation) evaluateBound(e *ast.BoundRef) (ast.Expr, error) {
+	value, ok := ev.bound[e.Name]
+	if !ok {
+		return nil, lerrors.Newf("Variable %v is not bound", e.Name)
+	}
+	if e.Path != "" {
+		if value, ok = lookup(value, e.Path); !ok {
+			return ev.missing(ast.String(e), lerrors.Newf("Cannot get %v of bound element: %w", ast.String(e), lerrors.ErrUnknownVariable))
+		}
+	}
+	return toLiteral(value)
+}
@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"strings"
+
+	lerrors "github.com/thenam153/conditions-go/errors"
+)
+
+// Value is an argument value, converted like the values of args
+type Value = any
+
+// Resolver fetches arguments on demand, path is the argument reference split
+// in segments: [a][b] is resolved with []string{"a", "b"}. A missing argument
+// is reported by an error wrapping errors.ErrUnknownVariable.
+type Resolver interface {
+	Resolve(path []string) (Value, error)
+}
+
+// DocumentResolver is a Resolver able to materialise all its arguments as one
+// document, JQ queries run against it
+type DocumentResolver interface {
+	Resolver
+	Document() (map[string]any, error)
+}
+
+type resolved struct {
+	value Value
+	ok    bool
+}
+
+// Look up the argument referenced by name, a Resolver is called at most once
+// per name and evaluation
+func (ev *evaluation) lookup(name string) (any, bool, error) {
+	r, ok := ev.args.(Resolver)
+	if !ok {
+		value, ok := lookup(ev.args, name)
+		return value, ok, nil
+	}
+	if res, ok := ev.resolved[name]; ok {
+		return res.value, res.ok, nil
+	}
+	value, err := r.Resolve(strings.Split(name, "."))
+	if err != nil && !lerrors.Is(err, lerrors.ErrUnknownVariable) {
+		return nil, false, err
+	}
+	if ev.resolved == nil {
+		ev.resolved = map[string]resolved{}
+	}
+	ev.resolved[name] = resolved{value: value, ok: err == nil}
+	return value, err == nil, nil
+}
+
+// Arguments as the input of JQ queries, materialised and normalised once per
+// evaluation
+func (ev *evaluation) document() (any, error) {
+	if ev.opts.jqInput != nil {
+		return *ev.opts.jqInput, nil
+	}
+	if ev.docReady {
+		return ev.doc, nil
+	}
+	args := ev.args
+	switch r := ev.args.(type) {
+	case DocumentResolver:
+		doc, err := r.Document()
+		if err != nil {
+			return nil, lerrors.NewWrap("Cannot materialise resolver document", err)
+		}
+		args = doc
+	case Resolver:
+		return nil, lerrors.Newf("Resolver %T cannot be materialised as a document: %w", r, lerrors.ErrJQ)
+	}
+	doc, err := NormalizeJQ(args)
+	if err != nil {
+		return nil, err
+	}
+	ev.doc, ev.docReady = doc, true
+	return doc, nil
+}
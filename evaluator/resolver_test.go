@@ -0,0 +1,138 @@
+package evaluator_test
+
+import (
+	"strings"
+	"testing"
+
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/evaluator"
+)
+
+// Resolver over a map counting its calls
+type countingResolver struct {
+	args      map[string]any
+	resolved  map[string]int
+	documents int
+}
+
+func newCountingResolver(args map[string]any) *countingResolver {
+	return &countingResolver{args: args, resolved: map[string]int{}}
+}
+
+func (r *countingResolver) Resolve(path []string) (evaluator.Value, error) {
+	name := strings.Join(path, ".")
+	r.resolved[name]++
+	value, ok := r.args[name]
+	if !ok {
+		return nil, lerrors.Newf("No argument %v: %w", name, lerrors.ErrUnknownVariable)
+	}
+	return value, nil
+}
+
+func (r *countingResolver) Document() (map[string]any, error) {
+	r.documents++
+	return r.args, nil
+}
+
+// Resolver with no document
+type plainResolver struct {
+	r *countingResolver
+}
+
+func (r plainResolver) Resolve(path []string) (evaluator.Value, error) {
+	return r.r.Resolve(path)
+}
+
+func TestResolver(t *testing.T) {
+	args := map[string]any{"a": 1.0, "b": "x", "t": true, "f": false, "user.name": "bob"}
+	tests := []struct {
+		expr string
+		want bool
+		// Calls by name, names not listed are never resolved
+		calls map[string]int
+	}{
+		{expr: `[a] == 1`, want: true, calls: map[string]int{"a": 1}},
+		{expr: `[a] == 1 AND [a] > 0 AND [a] < 2`, want: true, calls: map[string]int{"a": 1}},
+		{expr: `[a] + [a] == 2`, want: true, calls: map[string]int{"a": 1}},
+		{expr: `[user][name] == "bob"`, want: true, calls: map[string]int{"user.name": 1}},
+		// Short-circuited RHS
+		{expr: `[f] AND [b] == "x"`, want: false, calls: map[string]int{"f": 1}},
+		{expr: `[t] OR [b] == "x"`, want: true, calls: map[string]int{"t": 1}},
+		{expr: `[a] == 2 AND ([b] == "x" OR [c] == 1)`, want: false, calls: map[string]int{"a": 1}},
+		{expr: `[t] OR [missing] == 1`, want: true, calls: map[string]int{"t": 1}},
+		{expr: `[t] AND [b] == "x"`, want: true, calls: map[string]int{"t": 1, "b": 1}},
+		// A missing argument is resolved once too
+		{expr: `[c] IS NULL AND [c] IS NULL`, want: true, calls: map[string]int{"c": 1}},
+	}
+	for _, tt := range tests {
+		r := newCountingResolver(args)
+		got, err := evaluator.EvaluateResolver(parse(t, tt.expr), r)
+		if err != nil {
+			t.Errorf("%v: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v = %v, want %v", tt.expr, got, tt.want)
+		}
+		for name, n := range r.resolved {
+			if n != tt.calls[name] {
+				t.Errorf("%v: %v resolved %d times, want %d", tt.expr, name, n, tt.calls[name])
+			}
+		}
+		for name, n := range tt.calls {
+			if r.resolved[name] != n {
+				t.Errorf("%v: %v resolved %d times, want %d", tt.expr, name, r.resolved[name], n)
+			}
+		}
+		if r.documents != 0 {
+			t.Errorf("%v: document materialised", tt.expr)
+		}
+	}
+
+	// Every evaluation resolves again
+	r := newCountingResolver(args)
+	expr := parse(t, `[a] == 1`)
+	for i := 0; i < 2; i++ {
+		if _, err := evaluator.EvaluateResolver(expr, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.resolved["a"] != 2 {
+		t.Errorf("a resolved %d times over two evaluations, want 2", r.resolved["a"])
+	}
+}
+
+func TestDocumentResolver(t *testing.T) {
+	args := map[string]any{"a": 1.0, "xs": []any{1.0, 2.0}}
+	r := newCountingResolver(args)
+	got, err := evaluator.EvaluateResolver(parse(t, `$jq(.a) == 1 AND $jq[count](.xs[]) == 2 AND [a] == 1 AND $jq(.xs[1]) == 2`), r)
+	if err != nil || !got {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	if r.documents != 1 {
+		t.Errorf("document materialised %d times, want 1", r.documents)
+	}
+	if r.resolved["a"] != 1 {
+		t.Errorf("a resolved %d times, want 1", r.resolved["a"])
+	}
+
+	// Not materialised when short-circuited, nor with WithJQInput
+	r = newCountingResolver(args)
+	if _, err := evaluator.EvaluateResolver(parse(t, `[a] == 2 AND $jq(.a) == 1`), r); err != nil || r.documents != 0 {
+		t.Errorf("short-circuited: %v, document materialised %d times", err, r.documents)
+	}
+	r = newCountingResolver(args)
+	got, err = evaluator.EvaluateResolver(parse(t, `$jq(.a) == 5`), r, evaluator.WithJQInput(map[string]any{"a": 5}))
+	if err != nil || !got || r.documents != 0 {
+		t.Errorf("WithJQInput: %v, %v, document materialised %d times", got, err, r.documents)
+	}
+
+	// JQ needs a document
+	_, err = evaluator.EvaluateResolver(parse(t, `$jq(.a) == 1`), plainResolver{newCountingResolver(args)})
+	if !lerrors.Is(err, lerrors.ErrJQ) {
+		t.Errorf("resolver without document: got error %v, want ErrJQ", err)
+	}
+	if _, err := evaluator.EvaluateResolver(parse(t, `[a] == 1`), nil); err == nil {
+		t.Error("nil resolver: no error")
+	}
+}
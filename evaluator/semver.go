@@ -0,0 +1,78 @@
+package evaluator
+
+import (
+	"reflect"
+
+	"github.com/thenam153/conditions-go/ast"
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/semver"
+	"github.com/thenam153/conditions-go/token"
+)
+
+var versionType = reflect.TypeOf(semver.Version{})
+
+// A string compared with a version is parsed as one, strings that are not a
+// version are left for the operator to reject
+func coerceVersion(l, r ast.Expr) (ast.Expr, ast.Expr) {
+	parse := func(e ast.Expr) ast.Expr {
+		if s, ok := e.(*ast.StringLiteral); ok {
+			if v, err := semver.Parse(s.Value); err == nil {
+				return &ast.VersionLiteral{Span: s.Span, Value: v}
+			}
+		}
+		return e
+	}
+	switch {
+	case isVersion(l):
+		return l, parse(r)
+	case isVersion(r):
+		return parse(l), r
+	}
+	return l, r
+}
+
+func isVersion(e ast.Expr) bool {
+	_, ok := e.(*ast.VersionLiteral)
+	return ok
+}
+
+// Convert a version or version string, the value of semver()
+func toVersion(e ast.Expr) (*ast.VersionLiteral, error) {
+	switch v := e.(type) {
+	case *ast.VersionLiteral:
+		return v, nil
+	case *ast.StringLiteral:
+		version, err := semver.Parse(v.Value)
+		if err != nil {
+			return nil, lerrors.Wrap(lerrors.Newf("Cannot convert %q to version: %w", v.Value, lerrors.ErrUnsupportedValue), err)
+		}
+		return &ast.VersionLiteral{Value: version}, nil
+	}
+	return nil, lerrors.Newf("Cannot convert %v to version: %w", typeName(e), lerrors.ErrUnsupportedValue)
+}
+
+// Apply SATISFIES and NOT SATISFIES, a version or version string in a range.
+// Ranges of literals are parsed by the parser, ranges read from arguments on
+// every evaluation.
+func applySatisfies(op token.Token, l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	if _, ok := l.(*ast.StringLiteral); !ok && !isVersion(l) {
+		return nil, mismatch(op, l, r)
+	}
+	var c *semver.Constraint
+	switch rv := r.(type) {
+	case *ast.ConstraintLiteral:
+		c = rv.Value
+	case *ast.StringLiteral:
+		var err error
+		if c, err = semver.ParseConstraint(rv.Value); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, mismatch(op, l, r)
+	}
+	lv, err := toVersion(l)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.BooleanLiteral{Value: c.Check(lv.Value) == (op == token.SATISFIES)}, nil
+}
@@ -0,0 +1,37 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/thenam153/conditions-go/evaluator"
+)
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		expr string
+		args map[string]any
+		want bool
+		err  bool
+	}{
+		{expr: `[v] SATISFIES "^1.2"`, args: map[string]any{"v": "1.9.0"}, want: true},
+		{expr: `[v] SATISFIES "^1.2"`, args: map[string]any{"v": "2.0.0"}, want: false},
+		{expr: `[v] NOT SATISFIES "^1.2"`, args: map[string]any{"v": "2.0.0"}, want: true},
+		{expr: `semver("1.2.3") SATISFIES "~1.2"`, want: true},
+		// Ranges read from args are parsed on evaluation
+		{expr: `[v] SATISFIES [r]`, args: map[string]any{"v": "1.2.3", "r": ">=1 <2"}, want: true},
+		{expr: `[v] SATISFIES [r]`, args: map[string]any{"v": "1.2.3", "r": ">=a"}, err: true},
+		{expr: `[v] SATISFIES [r]`, args: map[string]any{"v": "1.2.3", "r": 1}, err: true},
+		{expr: `[v] SATISFIES "^1"`, args: map[string]any{"v": "not a version"}, err: true},
+		{expr: `[v] SATISFIES "^1"`, args: map[string]any{"v": 1}, err: true},
+	}
+	for _, tt := range tests {
+		got, err := evaluator.Evaluate(parse(t, tt.expr), tt.args)
+		if (err != nil) != tt.err {
+			t.Errorf("%v with %v: got error %v, want error %v", tt.expr, tt.args, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v with %v = %v, want %v", tt.expr, tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package evaluator
+
+import (
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/token"
+)
+
+// Apply a set operator between two arrays of the same element type, order
+// and duplicates do not matter
+func applySet(op token.Token, l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	ls, rs, err := setOperands(op, l, r)
+	if err != nil {
+		return nil, err
+	}
+	var result bool
+	switch op {
+	case token.INTERSECTS, token.DISJOINT:
+		// Probe the larger set with the elements of the smaller one
+		if len(ls) > len(rs) {
+			ls, rs = rs, ls
+		}
+		result = !subset(ls, rs, true)
+		if op == token.DISJOINT {
+			result = !result
+		}
+	case token.SUBSETOF, token.NOTSUBSETOF:
+		result = subset(ls, rs, false) == (op == token.SUBSETOF)
+	default:
+		result = subset(rs, ls, false) == (op == token.SUPERSETOF)
+	}
+	return &ast.BooleanLiteral{Value: result}, nil
+}
+
+// Report whether every element of a is in b, or with none, whether no
+// element of a is in b
+func subset(a, b map[any]struct{}, none bool) bool {
+	for v := range a {
+		if _, ok := b[v]; ok == none {
+			return false
+		}
+	}
+	return true
+}
+
+// Sets of the elements of two arrays, an empty array matches any type and a
+// list literal any array
+func setOperands(op token.Token, l, r ast.Expr) (map[any]struct{}, map[any]struct{}, error) {
+	ls, lok := toSet(l)
+	rs, rok := toSet(r)
+	if !lok || !rok {
+		return nil, nil, mismatch(op, l, r)
+	}
+	_, llist := l.(*ast.ListLiteral)
+	_, rlist := r.(*ast.ListLiteral)
+	_, lstrings := l.(*ast.SliceStringLiteral)
+	_, rstrings := r.(*ast.SliceStringLiteral)
+	if !llist && !rlist && lstrings != rstrings && len(ls) > 0 && len(rs) > 0 {
+		return nil, nil, mismatch(op, l, r)
+	}
+	return ls, rs, nil
+}
+
+func toSet(e ast.Expr) (map[any]struct{}, bool) {
+	switch v := e.(type) {
+	case *ast.SliceStringLiteral:
+		set := make(map[any]struct{}, len(v.Value))
+		for _, s := range v.Value {
+			set[s] = struct{}{}
+		}
+		return set, true
+	case *ast.SliceNumberLiteral:
+		set := make(map[any]struct{}, len(v.Value))
+		for _, n := range v.Value {
+			set[n] = struct{}{}
+		}
+		return set, true
+	case *ast.ListLiteral:
+		set := make(map[any]struct{}, len(v.Value))
+		for _, elem := range v.Value {
+			key, ok := elementKey(elem)
+			if !ok {
+				return nil, false
+			}
+			set[key] = struct{}{}
+		}
+		return set, true
+	}
+	return nil, false
+}
+
+// Set equality of two arrays, ok is false when l is not an array
+func setEqual(op token.Token, l, r ast.Expr) (equal bool, ok bool, err error) {
+	if _, ok := toSet(l); !ok {
+		return false, false, nil
+	}
+	ls, rs, err := setOperands(op, l, r)
+	if err != nil {
+		return false, true, err
+	}
+	return len(ls) == len(rs) && subset(ls, rs, false), true, nil
+}
@@ -0,0 +1,55 @@
+package evaluator_test
+
+import "testing"
+
+func TestSetOperators(t *testing.T) {
+	args := map[string]any{
+		"tags":  []string{"a", "b", "b", "a"},
+		"empty": []string{},
+		"nums":  []float64{1, 1, 2},
+		"none":  []any{},
+		"mixed": []any{1.0, "a", true},
+	}
+	runEvalTests(t, []evalTest{
+		// Duplicates do not matter
+		{expr: `[tags] INTERSECTS ["b", "b"]`, args: args, want: true},
+		{expr: `[tags] DISJOINT ["c", "c"]`, args: args, want: true},
+		{expr: `[tags] SUBSET OF ["a", "b"]`, args: args, want: true},
+		{expr: `["a", "b"] SUBSET OF [tags]`, args: args, want: true},
+		{expr: `[tags] SUPERSET OF ["a", "a", "a"]`, args: args, want: true},
+		{expr: `[tags] NOT SUPERSET OF ["a", "c"]`, args: args, want: true},
+		{expr: `[tags] == ["b", "a"]`, args: args, want: true},
+		{expr: `[tags] != ["a"]`, args: args, want: true},
+		{expr: `[nums] == [2, 1]`, args: args, want: true},
+		{expr: `[nums] SUBSET OF [1, 2, 3]`, args: args, want: true},
+		{expr: `[nums] SUPERSET OF [1, 2, 3]`, args: args, want: false},
+		{expr: `[nums] NOT SUBSET OF [1]`, args: args, want: true},
+		// The empty set is a subset of any set and intersects none
+		{expr: `[empty] INTERSECTS [tags]`, args: args, want: false},
+		{expr: `[tags] INTERSECTS [empty]`, args: args, want: false},
+		{expr: `[empty] INTERSECTS [empty]`, args: args, want: false},
+		{expr: `[empty] DISJOINT [empty]`, args: args, want: true},
+		{expr: `[empty] SUBSET OF [tags]`, args: args, want: true},
+		{expr: `[empty] SUBSET OF [empty]`, args: args, want: true},
+		{expr: `[tags] SUBSET OF [empty]`, args: args, want: false},
+		{expr: `[tags] SUPERSET OF []`, args: args, want: true},
+		{expr: `[] SUPERSET OF [tags]`, args: args, want: false},
+		{expr: `[empty] == []`, args: args, want: true},
+		// An empty array of any type matches any array
+		{expr: `[empty] SUBSET OF [nums]`, args: args, want: true},
+		{expr: `[nums] DISJOINT [empty]`, args: args, want: true},
+		{expr: `[none] SUBSET OF [nums]`, args: args, want: true},
+		// Lists compare elements by type and value
+		{expr: `[mixed] INTERSECTS ["a"]`, args: args, want: true},
+		{expr: `[mixed] INTERSECTS ["1"]`, args: args, want: false},
+		{expr: `[mixed] SUPERSET OF [TRUE, 1]`, args: args, want: true},
+		{expr: `[mixed] SUBSET OF [1, "a", TRUE, TRUE, NULL]`, args: args, want: true},
+		{expr: `[[1, 2], [3]] INTERSECTS [[2, 1, 1]]`, want: true},
+		// Arrays of different types, operands that are not arrays
+		{expr: `[tags] INTERSECTS [nums]`, args: args, err: errAny},
+		{expr: `[tags] SUBSET OF [1, 2]`, args: args, err: errAny},
+		{expr: `[tags] INTERSECTS "a"`, args: args, err: errAny},
+		{expr: `"a" SUBSET OF [tags]`, args: args, err: errAny},
+		{expr: `[tags] DISJOINT 1`, args: args, err: errAny},
+	})
+}
@@ -0,0 +1,60 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/internal/like"
+	"github.com/thenam153/conditions-go/token"
+)
+
+// Apply CONTAINS, STARTS WITH, ENDS WITH, LIKE, their case-insensitive and
+// negated forms. A slice LHS of CONTAINS is tested for membership.
+func applyString(op token.Token, l, r ast.Expr) (*ast.BooleanLiteral, error) {
+	positive := op
+	if isNegated(op) {
+		positive = op.Negate()
+	}
+	if positive == token.CONTAINS {
+		switch l.(type) {
+		case *ast.SliceStringLiteral, *ast.SliceNumberLiteral, *ast.ListLiteral:
+			found, err := contains(op, r, l)
+			if err != nil {
+				return nil, err
+			}
+			return &ast.BooleanLiteral{Value: found != (positive != op)}, nil
+		}
+	}
+	lv, err := getString(l)
+	if err != nil {
+		return nil, mismatch(op, l, r)
+	}
+	rv, err := getString(r)
+	if err != nil {
+		return nil, mismatch(op, l, r)
+	}
+	var match bool
+	switch positive {
+	case token.CONTAINS:
+		match = strings.Contains(lv, rv)
+	case token.ICONTAINS:
+		match = strings.Contains(strings.ToLower(lv), strings.ToLower(rv))
+	case token.STARTSWITH:
+		match = strings.HasPrefix(lv, rv)
+	case token.ENDSWITH:
+		match = strings.HasSuffix(lv, rv)
+	case token.LIKE:
+		match = like.Match(lv, rv)
+	case token.ILIKE:
+		match = like.Match(strings.ToLower(lv), strings.ToLower(rv))
+	}
+	return &ast.BooleanLiteral{Value: match != (positive != op)}, nil
+}
+
+func isNegated(op token.Token) bool {
+	switch op {
+	case token.NOTCONTAINS, token.NOTICONTAINS, token.NOTSTARTSWITH, token.NOTENDSWITH, token.NOTLIKE, token.NOTILIKE:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package evaluator_test
+
+import (
+	"testing"
+)
+
+func TestLike(t *testing.T) {
+	args := map[string]any{"s": "Straße 1", "p": "str%"}
+	runEvalTests(t, []evalTest{
+		{expr: `[s] LIKE "Stra_e %"`, args: args, want: true},
+		{expr: `[s] LIKE "stra%"`, args: args, want: false},
+		{expr: `[s] ILIKE "stra%"`, args: args, want: true},
+		{expr: `[s] ILIKE [p]`, args: args, want: true},
+		{expr: `[s] NOT LIKE "%1"`, args: args, want: false},
+		{expr: `[s] NOT ILIKE "%E 2"`, args: args, want: true},
+		{expr: `"a%" LIKE [e]`, args: map[string]any{"e": `a\%`}, want: true},
+		{expr: `"ab" LIKE [e]`, args: map[string]any{"e": `a\%`}, want: false},
+		{expr: `[s] LIKE 1`, args: args, err: errAny},
+	})
+}
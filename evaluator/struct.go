@@ -0,0 +1,66 @@
+package evaluator
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Field index paths by argument name, cached per struct type
+var structCache sync.Map
+
+func structFields(t reflect.Type) map[string][]int {
+	if fields, ok := structCache.Load(t); ok {
+		return fields.(map[string][]int)
+	}
+	fields := map[string][]int{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous && embedded(f.Type) {
+			continue
+		}
+		name := fieldName(f)
+		if name == "-" {
+			continue
+		}
+		// Shallower fields shadow promoted ones
+		if index, ok := fields[name]; ok && len(index) <= len(f.Index) {
+			continue
+		}
+		fields[name] = f.Index
+	}
+	actual, _ := structCache.LoadOrStore(t, fields)
+	return actual.(map[string][]int)
+}
+
+// Fields of embedded structs are promoted, the embedded field is not an argument
+func embedded(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+func fieldName(f reflect.StructField) string {
+	for _, key := range []string{"cond", "json"} {
+		if tag, ok := f.Tag.Lookup(key); ok {
+			if name, _, _ := strings.Cut(tag, ","); name != "" {
+				return name
+			}
+		}
+	}
+	return f.Name
+}
+
+// Like reflect.Value.FieldByIndex, the zero Value is returned through a nil
+// embedded pointer
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 {
+			if v = indirect(v); !v.IsValid() {
+				return v
+			}
+		}
+		v = v.Field(x)
+	}
+	return v
+}
@@ -0,0 +1,137 @@
+package evaluator_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/evaluator"
+	"github.com/thenam153/conditions-go/parser"
+)
+
+func parse(t testing.TB, src string) ast.Expr {
+	t.Helper()
+	expr, err := parser.NewParser(strings.NewReader(src)).Parse()
+	if err != nil {
+		t.Fatalf("%v: %v", src, err)
+	}
+	return expr
+}
+
+type Base struct {
+	ID   int `json:"id"`
+	Kind string
+}
+
+type Extra struct {
+	Note string `cond:"note"`
+}
+
+type base struct {
+	Hidden string
+}
+
+type Account struct {
+	Base
+	*Extra
+	base
+	// Tag precedence: cond, then json, then the field name
+	Name    string `cond:"name" json:"full_name"`
+	Email   string `json:"email,omitempty"`
+	Country string
+	Secret  string `json:"-"`
+	Kind    string `json:"kind"`
+	Ident   int    `json:"id"`
+	Owner   *Account
+	private string
+}
+
+// Same fields as Account, other names: the field cache is per type
+type Renamed struct {
+	Name string `cond:"label"`
+}
+
+func TestEvaluateStruct(t *testing.T) {
+	account := &Account{
+		Base:    Base{ID: 7, Kind: "base"},
+		base:    base{Hidden: "promoted"},
+		Name:    "bob",
+		Email:   "bob@example.com",
+		Country: "VN",
+		Secret:  "s",
+		Kind:    "admin",
+		Ident:   8,
+		Owner:   &Account{Name: "alice"},
+		private: "p",
+	}
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{field: "name", want: `"bob"`},
+		{field: "full_name", want: "NULL"},
+		{field: "Name", want: "NULL"},
+		{field: "email", want: `"bob@example.com"`},
+		{field: "Email", want: "NULL"},
+		{field: "Country", want: `"VN"`},
+		{field: "Secret", want: "NULL"},
+		{field: "private", want: "NULL"},
+		// Promoted fields, shadowed by shallower ones of the same Go or
+		// argument name
+		{field: "id", want: "8"},
+		{field: "kind", want: `"admin"`},
+		{field: "Kind", want: "NULL"},
+		{field: "Hidden", want: `"promoted"`},
+		{field: "Base", want: "NULL"},
+		// Through the nil *Extra
+		{field: "note", want: "NULL"},
+		{field: "Owner][name", want: `"alice"`},
+		{field: "Owner][Owner", want: "NULL"},
+	}
+	for _, tt := range tests {
+		path := strings.ReplaceAll(tt.field, "][", ".")
+		for _, src := range []string{"[" + tt.field + "]", "$jq(." + path + ")"} {
+			cond := src + " == " + tt.want
+			if tt.want == "NULL" {
+				cond = src + " IS NULL"
+			}
+			got, err := evaluator.EvaluateStruct(parse(t, cond), account, evaluator.WithMissing(evaluator.MissingNull))
+			if err != nil || !got {
+				t.Errorf("%v = %v, %v", cond, got, err)
+			}
+		}
+	}
+
+	// A struct value, not only a pointer
+	if got, err := evaluator.EvaluateStruct(parse(t, `[name] == "bob" AND $jq(.name) == "bob"`), *account); err != nil || !got {
+		t.Errorf("struct value: %v, %v", got, err)
+	}
+	for _, v := range []any{nil, (*Account)(nil), map[string]any{}, 1} {
+		if _, err := evaluator.EvaluateStruct(parse(t, `[name] == "bob"`), v); err == nil {
+			t.Errorf("EvaluateStruct(%#v): no error", v)
+		}
+	}
+}
+
+func TestEvaluateStructCache(t *testing.T) {
+	expr := parse(t, `[label] == "x" AND $jq(.label) == "x"`)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got, err := evaluator.EvaluateStruct(parse(t, `[name] == "x"`), Account{Name: "x"}); err != nil || !got {
+					t.Errorf("Account: %v, %v", got, err)
+					return
+				}
+				if got, err := evaluator.EvaluateStruct(expr, Renamed{Name: "x"}); err != nil || !got {
+					t.Errorf("Renamed: %v, %v", got, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,99 @@
+package evaluator
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/token"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// A string compared with a time is parsed as an RFC3339 time, strings that
+// are not a time are left for the operator to reject
+func coerceTime(l, r ast.Expr) (ast.Expr, ast.Expr) {
+	parse := func(e ast.Expr) ast.Expr {
+		if s, ok := e.(*ast.StringLiteral); ok {
+			if t, err := ast.ParseTime(s.Value); err == nil {
+				return &ast.TimeLiteral{Span: s.Span, Value: t}
+			}
+		}
+		return e
+	}
+	switch {
+	case isTime(l):
+		return l, parse(r)
+	case isTime(r):
+		return parse(l), r
+	}
+	return l, r
+}
+
+func isTime(e ast.Expr) bool {
+	_, ok := e.(*ast.TimeLiteral)
+	return ok
+}
+
+// Apply + or -: numbers, durations, a time shifted by a duration and the
+// duration between two times
+func applyArithmetic(op token.Token, l, r ast.Expr) (ast.Expr, error) {
+	// [created] - 7d with [created] an RFC3339 string
+	if _, ok := r.(*ast.DurationLiteral); ok {
+		_, l = coerceTime(&ast.TimeLiteral{}, l)
+	}
+	if _, ok := l.(*ast.DurationLiteral); ok {
+		_, r = coerceTime(&ast.TimeLiteral{}, r)
+	}
+	l, r = coerceTime(l, r)
+	sign := func(d time.Duration) time.Duration {
+		if op == token.SUB {
+			return -d
+		}
+		return d
+	}
+	switch lv := l.(type) {
+	case *ast.NumberLiteral:
+		if rv, ok := r.(*ast.NumberLiteral); ok {
+			if op == token.SUB {
+				return &ast.NumberLiteral{Value: lv.Value - rv.Value}, nil
+			}
+			return &ast.NumberLiteral{Value: lv.Value + rv.Value}, nil
+		}
+	case *ast.TimeLiteral:
+		switch rv := r.(type) {
+		case *ast.DurationLiteral:
+			return &ast.TimeLiteral{Value: lv.Value.Add(sign(rv.Value))}, nil
+		case *ast.TimeLiteral:
+			if op == token.SUB {
+				return &ast.DurationLiteral{Value: lv.Value.Sub(rv.Value)}, nil
+			}
+		}
+	case *ast.DurationLiteral:
+		switch rv := r.(type) {
+		case *ast.DurationLiteral:
+			return &ast.DurationLiteral{Value: lv.Value + sign(rv.Value)}, nil
+		case *ast.TimeLiteral:
+			if op == token.ADD {
+				return &ast.TimeLiteral{Value: rv.Value.Add(lv.Value)}, nil
+			}
+		}
+	}
+	return nil, mismatch(op, l, r)
+}
+
+// Time of now(), every call of one evaluation sees the same time
+func (ev *evaluation) clock() time.Time {
+	if !ev.nowSet {
+		if ev.opts.clock != nil {
+			ev.now = ev.opts.clock()
+		} else {
+			ev.now = time.Now()
+		}
+		ev.nowSet = true
+	}
+	return ev.now
+}
@@ -0,0 +1,72 @@
+package evaluator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thenam153/conditions-go/evaluator"
+)
+
+// The clock is read once per evaluation, whatever time it returns
+func TestNowReadOnce(t *testing.T) {
+	for _, now := range []time.Time{{}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)} {
+		calls := 0
+		clock := evaluator.WithClock(func() time.Time {
+			calls++
+			return now
+		})
+		expr := parse(t, `now() == now() AND now() - [t] == 0s AND hour(now()) == 0`)
+		for i := 1; i <= 2; i++ {
+			got, err := evaluator.Evaluate(expr, map[string]any{"t": now}, clock)
+			if err != nil || !got {
+				t.Fatalf("now() at %v: %v, %v", now, got, err)
+			}
+			if calls != i {
+				t.Errorf("now() at %v: clock read %d times over %d evaluations", now, calls, i)
+			}
+		}
+	}
+}
+
+func TestTimeArithmetic(t *testing.T) {
+	created := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	args := map[string]any{
+		"created": created,
+		"str":     "2026-03-10T12:00:00Z",
+		"ttl":     90 * time.Minute,
+		"n":       1.0,
+	}
+	clock := []evaluator.Option{evaluator.WithClock(func() time.Time { return created.Add(36 * time.Hour) })}
+	runEvalTests(t, []evalTest{
+		// Time shifted by a duration
+		{expr: `[created] + 1d == t"2026-03-11T12:00:00Z"`, args: args, want: true},
+		{expr: `[created] - 1w == t"2026-03-03T12:00:00Z"`, args: args, want: true},
+		{expr: `1h + [created] == t"2026-03-10T13:00:00Z"`, args: args, want: true},
+		{expr: `[created] + [ttl] == t"2026-03-10T13:30:00Z"`, args: args, want: true},
+		// RFC3339 strings are times next to a duration or a time
+		{expr: `[str] - 12h == t"2026-03-10"`, args: args, want: true},
+		{expr: `[str] == [created]`, args: args, want: true},
+		{expr: `[str] < t"2026-03-11"`, args: args, want: true},
+		// Duration between times, sums of durations
+		{expr: `t"2026-03-11T00:00:00Z" - [created] == 12h`, args: args, want: true},
+		{expr: `[created] - t"2026-03-11" == -12h`, args: args, want: true},
+		{expr: `1h30m + 30m == 2h`, want: true},
+		{expr: `1d - 1h == 23h`, want: true},
+		{expr: `[ttl] > 1h AND [ttl] < 2h`, args: args, want: true},
+		{expr: `-1.5h == -90m`, want: true},
+		// now() from the clock
+		{expr: `now() - [created] == 36h`, args: args, opts: clock, want: true},
+		{expr: `[created] > now() - 2d`, args: args, opts: clock, want: true},
+		{expr: `[created] BETWEEN now() - 2d AND now()`, args: args, opts: clock, want: true},
+		{expr: `hour(now()) == 0 AND dayOfWeek(now()) == 4`, opts: clock, want: true},
+		{expr: `now() > t"2000-01-01"`, want: true},
+		// Operands that do not add up
+		{expr: `[created] + [created] > [created]`, args: args, err: errAny},
+		{expr: `1h - [created] > 1h`, args: args, err: errAny},
+		{expr: `[created] + 1 > [created]`, args: args, err: errAny},
+		{expr: `1h + 1 > 1h`, err: errAny},
+		{expr: `[n] + 1h > 1h`, args: args, err: errAny},
+		{expr: `"not a time" - 1h > 1h`, err: errAny},
+		{expr: `hour("not a time") == 1`, err: errAny},
+	})
+}
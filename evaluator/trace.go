@@ -0,0 +1,57 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thenam153/conditions-go/ast"
+)
+
+// TraceStep is the evaluation of a single node. Steps are stored in
+// pre-order, Depth is the distance from the root expression.
+type TraceStep struct {
+	Expr   ast.Expr
+	Result ast.Expr
+	Err    error
+	Depth  int
+}
+
+// Trace collects the evaluation steps of an expression
+type Trace struct {
+	Steps []TraceStep
+}
+
+func (t *Trace) begin(expr ast.Expr, depth int) int {
+	t.Steps = append(t.Steps, TraceStep{Expr: expr, Depth: depth})
+	return len(t.Steps) - 1
+}
+
+func (t *Trace) end(step int, result ast.Expr, err error) {
+	t.Steps[step].Result = result
+	t.Steps[step].Err = err
+}
+
+// Reset removes all recorded steps so the trace can be reused
+func (t *Trace) Reset() {
+	t.Steps = t.Steps[:0]
+}
+
+// String returns the trace as an indented tree, one node per line
+//
+//	[a] > 1 AND [b] == "x" => TRUE
+//	  [a] > 1 => TRUE
+//	    [a] => 2
+//	    1 => 1
+func (t *Trace) String() string {
+	var sb strings.Builder
+	for _, step := range t.Steps {
+		sb.WriteString(strings.Repeat("  ", step.Depth))
+		sb.WriteString(ast.String(step.Expr))
+		if step.Err != nil {
+			fmt.Fprintf(&sb, " => error: %v\n", step.Err)
+			continue
+		}
+		fmt.Fprintf(&sb, " => %v\n", ast.String(step.Result))
+	}
+	return sb.String()
+}
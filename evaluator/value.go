@@ -0,0 +1,215 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thenam153/conditions-go/ast"
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/semver"
+)
+
+var jsonNumberType = reflect.TypeOf(json.Number(""))
+
+// Strings compared with a time, a version or an IP address are parsed as one
+func coerce(l, r ast.Expr) (ast.Expr, ast.Expr) {
+	l, r = coerceTime(l, r)
+	l, r = coerceVersion(l, r)
+	return coerceIP(l, r)
+}
+
+// Look up the argument referenced by name. A flat key, e.g. "foo.bar", wins
+// over the nested path args["foo"]["bar"], path segments index maps with
+// string keys, struct fields, slices and arrays.
+func lookup(args any, name string) (any, bool) {
+	if m, ok := args.(map[string]any); ok {
+		if value, ok := m[name]; ok {
+			return value, true
+		}
+		if !strings.Contains(name, ".") {
+			return nil, false
+		}
+	}
+	v := reflect.ValueOf(args)
+	for _, segment := range strings.Split(name, ".") {
+		if v = indirect(v); !v.IsValid() {
+			return nil, false
+		}
+		switch v.Kind() {
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			v = v.MapIndex(reflect.ValueOf(segment).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return nil, false
+			}
+		case reflect.Slice, reflect.Array:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= v.Len() {
+				return nil, false
+			}
+			v = v.Index(i)
+		case reflect.Struct:
+			index, ok := structFields(v.Type())[segment]
+			if !ok {
+				return nil, false
+			}
+			if v = fieldByIndex(v, index); !v.IsValid() {
+				return nil, true
+			}
+		default:
+			return nil, false
+		}
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Interface && v.IsNil()) {
+		return nil, true
+	}
+	return v.Interface(), true
+}
+
+// Resolve pointers and interfaces, the zero Value stands for nil
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// Convert an argument value to a literal expression. Named types are
+// converted by kind, pointers are followed and slices converted element-wise.
+func toLiteral(value any) (ast.Expr, error) {
+	// Fast path for the common types
+	switch v := value.(type) {
+	case nil:
+		return &ast.NullLiteral{}, nil
+	case string:
+		return &ast.StringLiteral{Value: v}, nil
+	case float64:
+		return &ast.NumberLiteral{Value: v}, nil
+	case int:
+		return &ast.NumberLiteral{Value: float64(v)}, nil
+	case bool:
+		return &ast.BooleanLiteral{Value: v}, nil
+	case []string:
+		return &ast.SliceStringLiteral{Value: v}, nil
+	case []float64:
+		return &ast.SliceNumberLiteral{Value: v}, nil
+	case *big.Int:
+		// Integers beyond int64 computed by JQ
+		n, _ := new(big.Float).SetInt(v).Float64()
+		return &ast.NumberLiteral{Value: n}, nil
+	case time.Time:
+		return &ast.TimeLiteral{Value: v}, nil
+	case time.Duration:
+		return &ast.DurationLiteral{Value: v}, nil
+	case semver.Version:
+		return &ast.VersionLiteral{Value: v}, nil
+	}
+	v := indirect(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return &ast.NullLiteral{}, nil
+	}
+	switch v.Type() {
+	case timeType:
+		return &ast.TimeLiteral{Value: v.Interface().(time.Time)}, nil
+	case durationType:
+		return &ast.DurationLiteral{Value: time.Duration(v.Int())}, nil
+	case versionType:
+		return &ast.VersionLiteral{Value: v.Interface().(semver.Version)}, nil
+	}
+	if ip, ok := ipLiteral(v); ok {
+		return ip, nil
+	}
+	if v.Type() == jsonNumberType {
+		n, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil {
+			return nil, lerrors.Newf("Cannot convert json.Number %q to number: %w", v.String(), lerrors.ErrUnsupportedValue)
+		}
+		return &ast.NumberLiteral{Value: n}, nil
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &ast.NumberLiteral{Value: float64(v.Int())}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &ast.NumberLiteral{Value: float64(v.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return &ast.NumberLiteral{Value: v.Float()}, nil
+	case reflect.String:
+		return &ast.StringLiteral{Value: v.String()}, nil
+	case reflect.Bool:
+		return &ast.BooleanLiteral{Value: v.Bool()}, nil
+	case reflect.Slice, reflect.Array:
+		return toSliceLiteral(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return &ast.NullLiteral{}, nil
+		}
+		if v.Type().Key().Kind() == reflect.String {
+			object, err := normalize(v)
+			if err != nil {
+				return nil, err
+			}
+			return &ast.ObjectLiteral{Value: object.(map[string]any)}, nil
+		}
+	}
+	return nil, lerrors.Newf("Cannot convert %T to a value: %w", value, lerrors.ErrUnsupportedValue)
+}
+
+// Slices of strings or of numbers are slice literals, other slices list
+// literals. An empty slice is typed by its element kind.
+func toSliceLiteral(v reflect.Value) (ast.Expr, error) {
+	var (
+		arrString []string
+		arrNumber []float64
+		elems     = make([]ast.Expr, v.Len())
+	)
+	for i := range elems {
+		elem, err := toLiteral(v.Index(i).Interface())
+		if err != nil {
+			return nil, lerrors.NewWrap("Cannot convert element "+strconv.Itoa(i), err)
+		}
+		switch e := elem.(type) {
+		case *ast.StringLiteral:
+			arrString = append(arrString, e.Value)
+		case *ast.NumberLiteral:
+			arrNumber = append(arrNumber, e.Value)
+		}
+		elems[i] = elem
+	}
+	switch {
+	case len(elems) == 0:
+	case len(arrNumber) == len(elems):
+		return &ast.SliceNumberLiteral{Value: arrNumber}, nil
+	case len(arrString) == len(elems):
+		return &ast.SliceStringLiteral{Value: arrString}, nil
+	default:
+		return &ast.ListLiteral{Value: elems}, nil
+	}
+	switch v.Type().Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return &ast.SliceNumberLiteral{Value: []float64{}}, nil
+	}
+	return &ast.SliceStringLiteral{Value: []string{}}, nil
+}
+
+func isEmptySlice(e ast.Expr) bool {
+	switch v := e.(type) {
+	case *ast.ListLiteral:
+		return len(v.Value) == 0
+	case *ast.SliceStringLiteral:
+		return len(v.Value) == 0
+	case *ast.SliceNumberLiteral:
+		return len(v.Value) == 0
+	}
+	return false
+}
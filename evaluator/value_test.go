@@ -0,0 +1,131 @@
+package evaluator_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/evaluator"
+)
+
+type (
+	level   int
+	ratio   float32
+	name    string
+	flag    bool
+	names   []name
+	key     string
+	catalog map[key]any
+)
+
+func TestArgumentValues(t *testing.T) {
+	n := 3
+	np := &n
+	var nilInt *int
+	var nilMap map[string]any
+	var nilSlice []string
+	var nilNumbers []int
+	tests := []struct {
+		expr string
+		args map[string]any
+		want bool
+		err  error
+	}{
+		// Unsigned integers beyond int64 are not wrapped to negative numbers
+		{expr: `[u] > 1e19`, args: map[string]any{"u": uint64(math.MaxUint64)}, want: true},
+		{expr: `[u] == 18446744073709551615`, args: map[string]any{"u": uint64(math.MaxUint64)}, want: true},
+		{expr: `$jq(.u) > 1e19`, args: map[string]any{"u": uint64(math.MaxUint64)}, want: true},
+		{expr: `[u] == 255`, args: map[string]any{"u": uint8(255)}, want: true},
+		{expr: `[i] < -9e18`, args: map[string]any{"i": int64(math.MinInt64)}, want: true},
+		{expr: `[us] == [0, 18446744073709551615]`, args: map[string]any{"us": []uint64{0, math.MaxUint64}}, want: true},
+		// json.Number
+		{expr: `[n] == 1.5`, args: map[string]any{"n": json.Number("1.5")}, want: true},
+		{expr: `[n] > 1e300`, args: map[string]any{"n": json.Number("1e301")}, want: true},
+		{expr: `[ns] == [1, 2]`, args: map[string]any{"ns": []any{json.Number("1"), json.Number("2")}}, want: true},
+		{expr: `$jq(.n) == 12345678901234567890`, args: map[string]any{"n": json.Number("12345678901234567890")}, want: true},
+		{expr: `[n] == 1`, args: map[string]any{"n": json.Number("one")}, err: lerrors.ErrUnsupportedValue},
+		// Named types by kind
+		{expr: `[l] == 2`, args: map[string]any{"l": level(2)}, want: true},
+		{expr: `[r] == 0.5`, args: map[string]any{"r": ratio(0.5)}, want: true},
+		{expr: `[s] == "x"`, args: map[string]any{"s": name("x")}, want: true},
+		{expr: `[b]`, args: map[string]any{"b": flag(true)}, want: true},
+		{expr: `[ss] == ["a", "b"]`, args: map[string]any{"ss": names{"a", "b"}}, want: true},
+		{expr: `[c][k] == 1`, args: map[string]any{"c": catalog{"k": 1}}, want: true},
+		{expr: `$jq(.c.k) == 1`, args: map[string]any{"c": catalog{"k": 1}}, want: true},
+		// Pointers are followed, nil is NULL
+		{expr: `[p] == 3`, args: map[string]any{"p": np}, want: true},
+		{expr: `[pp] == 3`, args: map[string]any{"pp": &np}, want: true},
+		{expr: `[p] IS NULL`, args: map[string]any{"p": nilInt}, want: true},
+		{expr: `[p] == 3`, args: map[string]any{"p": nilInt}, want: false},
+		{expr: `[m] IS NULL`, args: map[string]any{"m": nilMap}, want: true},
+		{expr: `[m][k] IS NULL`, args: map[string]any{"m": nilMap}, want: true},
+		// A nil slice is empty, typed by its element kind
+		{expr: `[s] == []`, args: map[string]any{"s": nilSlice}, want: true},
+		{expr: `[s] NOT CONTAINS "a"`, args: map[string]any{"s": nilSlice}, want: true},
+		{expr: `[s] INTERSECTS [1]`, args: map[string]any{"s": nilNumbers}, want: false},
+		// []any of strings or numbers is typed, of mixed types a list
+		{expr: `[xs] == [1, 2.5, 3]`, args: map[string]any{"xs": []any{1, 2.5, uint8(3)}}, want: true},
+		{expr: `[xs] == ["a", "b"]`, args: map[string]any{"xs": []any{"a", name("b")}}, want: true},
+		{expr: `[xs] == [1, "a", TRUE, NULL]`, args: map[string]any{"xs": []any{1, "a", true, nil}}, want: true},
+		{expr: `[xs] == [[1], ["a"]]`, args: map[string]any{"xs": []any{[]int{1}, []any{"a"}}}, want: true},
+		{expr: `"a" IN [xs]`, args: map[string]any{"xs": []any{1, "a"}}, want: true},
+		{expr: `[xs] == []`, args: map[string]any{"xs": []any{}}, want: true},
+		{expr: `[xs] > 1`, args: map[string]any{"xs": []any{1, "a"}}, err: errAny},
+		{expr: `[xs] == [1]`, args: map[string]any{"xs": []any{1, struct{}{}}}, err: lerrors.ErrUnsupportedValue},
+		{expr: `[v] == 1`, args: map[string]any{"v": struct{}{}}, err: lerrors.ErrUnsupportedValue},
+		{expr: `[v] == 1`, args: map[string]any{"v": make(chan int)}, err: lerrors.ErrUnsupportedValue},
+	}
+	for _, tt := range tests {
+		got, err := evaluator.Evaluate(parse(t, tt.expr), tt.args)
+		switch {
+		case tt.err == nil && err != nil:
+			t.Errorf("%v with %v: %v", tt.expr, tt.args, err)
+		case tt.err != nil && (err == nil || tt.err != errAny && !lerrors.Is(err, tt.err)):
+			t.Errorf("%v with %v: got error %v, want %v", tt.expr, tt.args, err, tt.err)
+		case got != tt.want:
+			t.Errorf("%v with %v = %v, want %v", tt.expr, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		expr string
+		args map[string]any
+		want bool
+	}{
+		// A flat key wins over the nested path
+		{expr: `[foo][bar] == 1`, args: map[string]any{"foo.bar": 1, "foo": map[string]any{"bar": 2}}, want: true},
+		{expr: `[foo][bar] == 2`, args: map[string]any{"foo": map[string]any{"bar": 2}}, want: true},
+		{expr: `[foo][bar][baz] == 3`, args: map[string]any{"foo.bar": map[string]any{"baz": 2}, "foo": map[string]any{"bar": map[string]any{"baz": 3}}}, want: true},
+		// Segments index maps, slices, arrays and struct fields
+		{expr: `[xs][1] == "b"`, args: map[string]any{"xs": []string{"a", "b"}}, want: true},
+		{expr: `[xs][1] == 2`, args: map[string]any{"xs": [2]int{1, 2}}, want: true},
+		{expr: `[items][0][name] == "x"`, args: map[string]any{"items": []item{{Name: "x"}}}, want: true},
+		{expr: `[items][0][name] == "x"`, args: map[string]any{"items": []*item{{Name: "x"}}}, want: true},
+		{expr: `[m][k] == 1`, args: map[string]any{"m": map[key]int{"k": 1}}, want: true},
+		// Missing segments
+		{expr: `[xs][2] IS NULL`, args: map[string]any{"xs": []string{"a", "b"}}, want: true},
+		{expr: `EXISTS [xs][2]`, args: map[string]any{"xs": []string{"a", "b"}}, want: false},
+		{expr: `EXISTS [xs][x]`, args: map[string]any{"xs": []string{"a", "b"}}, want: false},
+		{expr: `EXISTS [m][1]`, args: map[string]any{"m": map[int]int{1: 1}}, want: false},
+		{expr: `EXISTS [s][x]`, args: map[string]any{"s": "text"}, want: false},
+		{expr: `EXISTS [items][0][Name]`, args: map[string]any{"items": []item{{}}}, want: false},
+		{expr: `EXISTS [p][name]`, args: map[string]any{"p": (*item)(nil)}, want: false},
+		{expr: `EXISTS [foo][bar]`, args: map[string]any{"foo.bar": nil}, want: true},
+	}
+	for _, tt := range tests {
+		got, err := evaluator.Evaluate(parse(t, tt.expr), tt.args)
+		if err != nil {
+			t.Errorf("%v with %v: %v", tt.expr, tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v with %v = %v, want %v", tt.expr, tt.args, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,9 @@ module github.com/thenam153/conditions-go
 
 go 1.20
 
-require github.com/itchyny/gojq v0.12.13
+require (
+	github.com/itchyny/gojq v0.12.13
+	golang.org/x/text v0.14.0
+)
 
 require github.com/itchyny/timefmt-go v0.1.5 // indirect
@@ -0,0 +1,87 @@
+// Package like matches strings against SQL LIKE patterns: % matches any
+// sequence of characters, _ a single character and a backslash escapes the
+// next character. A trailing backslash matches itself.
+package like
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Match reports whether s matches pattern
+func Match(s, pattern string) bool {
+	// Position to resume from in s and pattern after the last %
+	starS, starP := -1, -1
+	i, j := 0, 0
+	for i < len(s) {
+		if j < len(pattern) {
+			p, n := utf8.DecodeRuneInString(pattern[j:])
+			switch p {
+			case '%':
+				starS, starP = i, j+n
+				j += n
+				continue
+			case '_':
+				_, m := utf8.DecodeRuneInString(s[i:])
+				i, j = i+m, j+n
+				continue
+			case '\\':
+				if j+n < len(pattern) {
+					p, m := utf8.DecodeRuneInString(pattern[j+n:])
+					n += m
+					if c, k := utf8.DecodeRuneInString(s[i:]); c == p {
+						i, j = i+k, j+n
+						continue
+					}
+					break
+				}
+				fallthrough
+			default:
+				if c, k := utf8.DecodeRuneInString(s[i:]); c == p {
+					i, j = i+k, j+n
+					continue
+				}
+			}
+		}
+		// Mismatch, let the last % match one more character
+		if starP < 0 {
+			return false
+		}
+		_, m := utf8.DecodeRuneInString(s[starS:])
+		starS += m
+		i, j = starS, starP
+	}
+	for j < len(pattern) && pattern[j] == '%' {
+		j++
+	}
+	return j == len(pattern)
+}
+
+// Regex returns the regular expression matching the strings pattern
+// matches, e.g. to generate strings matching it
+func Regex(pattern string) string {
+	var sb strings.Builder
+	sb.WriteString("(?s)^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			sb.WriteString(".*")
+		case r == '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if escaped {
+		sb.WriteString(regexp.QuoteMeta("\\"))
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
@@ -0,0 +1,22 @@
+package like_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/thenam153/conditions-go/internal/like"
+)
+
+// Regex must agree with Match, casegen and the fuzz reference rely on it
+func TestRegex(t *testing.T) {
+	patterns := []string{"", "%", "_", "a%", "%a", "a_c", "%b%", `a\%`, `a\_c`, `\\`, `a\`, "ü_", "%%a%%", "a.c", "(a)*"}
+	values := []string{"", "a", "ab", "abc", "a_c", "a%", `a\`, `\`, "üx", "ba", "aa", "a.c", "(a)*", "a\nc"}
+	for _, p := range patterns {
+		re := regexp.MustCompile(like.Regex(p))
+		for _, s := range values {
+			if got, want := like.Match(s, p), re.MatchString(s); got != want {
+				t.Errorf("Match(%q, %q) = %v, regex %v", s, p, got, want)
+			}
+		}
+	}
+}
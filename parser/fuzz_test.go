@@ -0,0 +1,422 @@
+package parser_test
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/evaluator"
+	"github.com/thenam153/conditions-go/internal/like"
+	"github.com/thenam153/conditions-go/parser"
+	"github.com/thenam153/conditions-go/token"
+)
+
+var seeds = []string{
+	`[a] == 1`,
+	`[a][b] > -1.5 AND [c] != "x"`,
+	`[a] < 10 OR [b] <= 2 XOR [c] >= 3 NAND [d] > 4`,
+	`([a] == "x" OR [b] == "y") AND [c] NOT IN [1, 2, 3]`,
+	`"foo" IN ["foo", "bar"]`,
+	`[name] =~ /^b.b$/ AND [name] !~ "x"`,
+	`[name] =~ /a b\/c/`,
+	`$jq(.a.b) == 1 AND $jq[last](.items[] | .id) == "x"`,
+	`$jq[array](.items[].id) IN [1]`,
+	`$1 == TRUE AND [ok] == NOT FALSE`,
+	`[@timestamp] > 0`,
+	`[a] == 1 )`,
+	`["a"]`,
+	`'`,
+	`/abc`,
+	`"abc`,
+	`[a] IN [`,
+	`(((TRUE)))`,
+	`[b] < 2.5`,
+	`[c] IN ["x"] AND [a] NOT IN [1]`,
+	`/a"b/ == "x"`,
+	`[m] IS NULL OR [a] IS NOT NULL AND EXISTS [c]`,
+	`[m] == NULL AND NULL != [a] == 1`,
+	`[a] IS`,
+	`EXISTS 1`,
+	`[m][x] == 1 AND "y" IN [m][tags] AND [list][0] == 1`,
+	`$jq[last](range(1e9)) == 1`,
+	`$jq[array](repeat(1)) IN [1]`,
+	`$jq[count](.tags[]) == 2 AND $jq[any](.d) AND $jq(.m) IS NOT NULL`,
+	`[name] CONTAINS "o" AND [name] NOT STARTS WITH "x" OR [c] ENDS WITH "x"`,
+	`[name] EQI "BOB" AND [c] NOT EQI "y"`,
+	`[name] >= "b" AND [c] < "y" AND [d] > FALSE`,
+	`[name] LIKE "b%_" AND [name] NOT ILIKE "B\%" AND [tags] CONTAINS "x"`,
+	`[t] > now() - 7d AND [t] + 1h30m <= t"2026-01-02T00:00:00Z"`,
+	`hour([t]) >= 9 AND dayOfWeek("2026-01-01T00:00:00Z") != 0 OR [dur] < 90m`,
+	`[a] + 1 - -2.5 == [b] - [a] + 4`,
+	`now() - [t] > -1d12h`,
+	`semver([ver]) > "1.9.0" AND semver([ver]) IN ["1.10.0", "2.0.0-rc.1"]`,
+	`[ver] SATISFIES "^1.2 || >=3.1.0 <4" AND [ver] NOT SATISFIES "1.2.3 - 1.9.x"`,
+	`[ip] IN CIDR ["10.0.0.0/8", "2001:db8::/32"] OR [ip] NOT IN CIDR "10.1.2.3" AND ip([ip]) > ip("::1")`,
+	`(ANY x IN [tags] : x STARTS WITH "x") AND ALL i IN [items] : i.qty > 0 OR NONE n IN [1, 2] : n == [a]`,
+	`ANY o IN [items] : (ANY t IN o.tags : t == "y" AND o.tags.0 == "x") OR [a] == 1`,
+	`[tags] INTERSECTS ["y", "z"] AND [tags] SUBSET OF ["x", "y"] OR [tags] NOT SUPERSET OF ["x"]`,
+	`[tags] DISJOINT ["a"] AND [tags] == ["y", "x", "x"] AND [nums] != [2, 1]`,
+	`[a] BETWEEN 0 AND 2 AND [b] NOT BETWEEN 1 AND 2.5 EXCLUSIVE OR [a] BETWEEN [b] - 1 AND [b] + 1 INCLUSIVE`,
+	`[t] BETWEEN now() - 7d AND now() AND [name] NOT BETWEEN "a" AND "c"`,
+	`[a] IN [1, "x", TRUE] AND [tags] == ["x", 1] OR [a] NOT IN [] AND [b] IN [[1], -2.5]`,
+	`[[1, 2], [], [NULL, t"2026-01-01", 7d, "a\"b"]] SUPERSET OF [[2, 1]] AND ANY x IN [FALSE, 1] : x == 1`,
+}
+
+var fuzzArgs = map[string]any{
+	"a":          1,
+	"b":          2.5,
+	"c":          "x",
+	"d":          true,
+	"a.b":        -1.5,
+	"name":       "bob",
+	"ok":         false,
+	"$1":         true,
+	"@timestamp": 10,
+	"tags":       []string{"x", "y"},
+	"nums":       []float64{1, 2},
+	"m":          map[string]any{"x": uint8(1), "tags": []any{"y"}},
+	"list":       []any{1, "a"},
+	"t":          time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+	"dur":        time.Hour,
+	"ver":        "1.10.0",
+	"ip":         "10.1.2.3",
+	"items":      []any{map[string]any{"qty": 1, "tags": []string{"x"}}, map[string]any{"qty": 0}},
+}
+
+func parse(src string) (ast.Expr, error) {
+	return parser.NewParser(strings.NewReader(src)).Parse()
+}
+
+// FuzzParse checks the parser never panics and the printer round-trips:
+// printing a parsed expression and parsing it again gives the same output.
+func FuzzParse(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		expr, err := parse(src)
+		if err != nil {
+			return
+		}
+		printed := ast.String(expr)
+		again, err := parse(printed)
+		if err != nil {
+			t.Fatalf("cannot parse printed expression %q of %q: %v", printed, src, err)
+		}
+		if reprinted := ast.String(again); reprinted != printed {
+			t.Fatalf("printer does not round-trip %q: %q != %q", src, reprinted, printed)
+		}
+	})
+}
+
+// FuzzPrecedence checks both precedence versions agree on fully
+// parenthesised input, i.e. on the printed form of a parsed expression
+// where every binary expression is wrapped in parentheses.
+func FuzzPrecedence(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		expr, err := parse(src)
+		if err != nil {
+			return
+		}
+		full := parenthesise(expr)
+		defer token.SetVersion(1)
+		results := make([]string, 0, 2)
+		for _, v := range []int{0, 1} {
+			token.SetVersion(v)
+			expr, err := parse(full)
+			if err != nil {
+				t.Fatalf("cannot parse %q with version %d: %v", full, v, err)
+			}
+			results = append(results, ast.String(expr))
+		}
+		if results[0] != results[1] {
+			t.Fatalf("precedence versions disagree on %q: %q != %q", full, results[0], results[1])
+		}
+	})
+}
+
+// FuzzEvaluate checks parse and evaluate never panic, and evaluation agrees
+// with a reference implementation when it defines a result.
+func FuzzEvaluate(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		expr, err := parse(src)
+		if err != nil {
+			return
+		}
+		// JQ queries are unbounded (range(1e9), repeat)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		got, err := evaluator.EvaluateContext(ctx, expr, fuzzArgs,
+			evaluator.WithMaxJQResults(1000), evaluator.WithMaxNodes(10000))
+		want, ok := reference(expr)
+		if !ok {
+			return
+		}
+		b, isBool := want.(bool)
+		if !isBool {
+			return
+		}
+		if err != nil {
+			t.Fatalf("evaluate %q: unexpected error: %v", src, err)
+		}
+		if got != b {
+			t.Fatalf("evaluate %q: got %v, reference %v", src, got, b)
+		}
+	})
+}
+
+func parenthesise(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return parenthesise(e.Expr)
+	case *ast.BinaryExpr:
+		return "(" + parenthesise(e.LHS) + " " + e.OP.String() + " " + parenthesise(e.RHS) + ")"
+	case *ast.UnaryExpr:
+		if e.OP.IsPostfix() {
+			return "(" + parenthesise(e.Expr) + " " + e.OP.String() + ")"
+		}
+	case *ast.QuantifierExpr:
+		return "(" + e.OP.String() + " " + e.Var + " IN " + ast.String(e.Source) + " : " + parenthesise(e.Body) + ")"
+	case *ast.RangeExpr:
+		s := parenthesise(e.Low) + " AND " + parenthesise(e.High)
+		if e.Exclusive {
+			s += " EXCLUSIVE"
+		}
+		return s
+	}
+	return ast.String(expr)
+}
+
+// Reference semantics of the evaluator, ok is false when the result is
+// not defined (error expected or unsupported node)
+func reference(expr ast.Expr) (any, bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return reference(e.Expr)
+	case *ast.StringLiteral:
+		return e.Value, true
+	case *ast.NumberLiteral:
+		return e.Value, true
+	case *ast.BooleanLiteral:
+		return e.Value, true
+	case *ast.SliceStringLiteral:
+		return e.Value, true
+	case *ast.SliceNumberLiteral:
+		return e.Value, true
+	case *ast.TimeLiteral:
+		return e.Value, true
+	case *ast.DurationLiteral:
+		return e.Value, true
+	case *ast.VarRef:
+		switch v := fuzzArgs[e.Value].(type) {
+		case int:
+			return float64(v), true
+		case float64, string, bool, []string, []float64, time.Time, time.Duration:
+			return v, true
+		}
+		return nil, false
+	case *ast.BinaryExpr:
+		l, ok := reference(e.LHS)
+		if !ok {
+			return nil, false
+		}
+		if rng, ok := e.RHS.(*ast.RangeExpr); ok {
+			return referenceBetween(e.OP, l, rng)
+		}
+		r, ok := reference(e.RHS)
+		if !ok {
+			return nil, false
+		}
+		return referenceOperator(e.OP, l, r)
+	}
+	return nil, false
+}
+
+func referenceBetween(op token.Token, v any, rng *ast.RangeExpr) (any, bool) {
+	x, ok := v.(float64)
+	if !ok {
+		return nil, false
+	}
+	l, ok := reference(rng.Low)
+	if !ok {
+		return nil, false
+	}
+	h, ok := reference(rng.High)
+	if !ok {
+		return nil, false
+	}
+	low, lok := l.(float64)
+	high, hok := h.(float64)
+	if !lok || !hok {
+		return nil, false
+	}
+	in := x >= low && x <= high
+	if rng.Exclusive {
+		in = x > low && x < high
+	}
+	return in == (op == token.BETWEEN), true
+}
+
+func referenceOperator(op token.Token, l, r any) (any, bool) {
+	switch lv := l.(type) {
+	case bool:
+		rv, ok := r.(bool)
+		if !ok {
+			return nil, false
+		}
+		switch op {
+		case token.AND:
+			return lv && rv, true
+		case token.NAND:
+			return !(lv && rv), true
+		case token.OR:
+			return lv || rv, true
+		case token.XOR:
+			return lv != rv, true
+		case token.EQ:
+			return lv == rv, true
+		case token.NEQ:
+			return lv != rv, true
+		}
+	case float64:
+		switch rv := r.(type) {
+		case float64:
+			switch op {
+			case token.EQ:
+				return lv == rv, true
+			case token.NEQ:
+				return lv != rv, true
+			case token.LT:
+				return lv < rv, true
+			case token.LTE:
+				return lv <= rv, true
+			case token.GT:
+				return lv > rv, true
+			case token.GTE:
+				return lv >= rv, true
+			case token.ADD:
+				return lv + rv, true
+			case token.SUB:
+				return lv - rv, true
+			}
+		case []float64:
+			if op != token.IN && op != token.NOTIN {
+				return nil, false
+			}
+			found := false
+			for _, v := range rv {
+				found = found || v == lv
+			}
+			return found == (op == token.IN), true
+		}
+	case string:
+		switch rv := r.(type) {
+		case string:
+			switch op {
+			case token.EQ:
+				return lv == rv, true
+			case token.NEQ:
+				return lv != rv, true
+			case token.EREG, token.NEREG:
+				re, err := regexp.Compile(rv)
+				if err != nil {
+					return nil, false
+				}
+				return re.MatchString(lv) == (op == token.EREG), true
+			case token.LT:
+				return lv < rv, true
+			case token.GTE:
+				return lv >= rv, true
+			case token.CONTAINS:
+				return strings.Contains(lv, rv), true
+			case token.STARTSWITH:
+				return strings.HasPrefix(lv, rv), true
+			case token.ENDSWITH:
+				return strings.HasSuffix(lv, rv), true
+			case token.LIKE, token.NOTLIKE:
+				return regexp.MustCompile(like.Regex(rv)).MatchString(lv) == (op == token.LIKE), true
+			}
+		case []string:
+			if op != token.IN && op != token.NOTIN {
+				return nil, false
+			}
+			found := false
+			for _, v := range rv {
+				found = found || v == lv
+			}
+			return found == (op == token.IN), true
+		}
+	case []string:
+		rv, ok := r.([]string)
+		if !ok {
+			return nil, false
+		}
+		in := func(a, b []string) (all, some bool) {
+			all = true
+			for _, x := range a {
+				found := false
+				for _, y := range b {
+					found = found || x == y
+				}
+				all, some = all && found, some || found
+			}
+			return all, some
+		}
+		lInR, some := in(lv, rv)
+		rInL, _ := in(rv, lv)
+		switch op {
+		case token.INTERSECTS:
+			return some, true
+		case token.DISJOINT:
+			return !some, true
+		case token.SUBSETOF:
+			return lInR, true
+		case token.SUPERSETOF:
+			return rInL, true
+		case token.EQ:
+			return lInR && rInL, true
+		}
+	case time.Time:
+		switch rv := r.(type) {
+		case time.Time:
+			switch op {
+			case token.EQ:
+				return lv.Equal(rv), true
+			case token.LT:
+				return lv.Before(rv), true
+			case token.GT:
+				return lv.After(rv), true
+			case token.SUB:
+				return lv.Sub(rv), true
+			}
+		case time.Duration:
+			switch op {
+			case token.ADD:
+				return lv.Add(rv), true
+			case token.SUB:
+				return lv.Add(-rv), true
+			}
+		}
+	case time.Duration:
+		if rv, ok := r.(time.Duration); ok {
+			switch op {
+			case token.LT:
+				return lv < rv, true
+			case token.ADD:
+				return lv + rv, true
+			case token.SUB:
+				return lv - rv, true
+			}
+		}
+	}
+	return nil, false
+}
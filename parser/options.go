@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/itchyny/gojq"
+)
+
+type Option func(*Parser)
+
+// WithStrictArrays rejects array literals mixing element types, e.g.
+// [1, "1"], NULL elements fit any array
+func WithStrictArrays() Option {
+	return func(p *Parser) {
+		p.strictArrays = true
+	}
+}
+
+// WithJQVariables declares variables JQ queries may use, e.g. "sku" for
+// $sku, their values are given to the evaluator with
+// evaluator.WithJQVariables
+func WithJQVariables(names ...string) Option {
+	return func(p *Parser) {
+		for _, name := range names {
+			if !strings.HasPrefix(name, "$") {
+				name = "$" + name
+			}
+			p.jqVariables = append(p.jqVariables, name)
+		}
+	}
+}
+
+// WithJQOptions compiles every JQ query with opts, e.g. gojq.WithFunction to
+// define functions in Go
+func WithJQOptions(opts ...gojq.CompilerOption) Option {
+	return func(p *Parser) {
+		p.jqOptions = append(p.jqOptions, opts...)
+	}
+}
@@ -0,0 +1,62 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/parser"
+)
+
+func TestQuantifierBody(t *testing.T) {
+	tests := []struct {
+		src string
+		// Fully parenthesised form
+		want string
+	}{
+		{`ANY x IN [xs] : x > 1`, `(ANY x IN [xs] : (x > 1))`},
+		{`ANY x IN [xs] : x > 1 AND [flag]`, `((ANY x IN [xs] : (x > 1)) AND [flag])`},
+		{`ALL x IN [xs] : x > 1 OR [flag]`, `((ALL x IN [xs] : (x > 1)) OR [flag])`},
+		{`NONE x IN [xs] : x == 1 XOR [a] NAND [b]`, `((NONE x IN [xs] : (x == 1)) XOR ([a] NAND [b]))`},
+		{`[flag] AND ANY x IN [xs] : x + 1 > 2 OR [b]`, `(([flag] AND (ANY x IN [xs] : ((x + 1) > 2))) OR [b])`},
+		{`ANY x IN [xs] : (x > 1 AND x < 5)`, `(ANY x IN [xs] : ((x > 1) AND (x < 5)))`},
+		{`ANY x IN [xs] : x BETWEEN 1 AND 5 AND [flag]`, `((ANY x IN [xs] : (x BETWEEN 1 AND 5)) AND [flag])`},
+		{`ANY x IN [xs] : x IS NULL OR [flag]`, `((ANY x IN [xs] : (x IS NULL)) OR [flag])`},
+		{`ANY o IN [os] : ANY t IN o.tags : t == "a" AND [flag]`, `((ANY o IN [os] : (ANY t IN o.tags : (t == "a"))) AND [flag])`},
+	}
+	for _, tt := range tests {
+		expr, err := parser.NewParser(strings.NewReader(tt.src)).Parse()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.src, err)
+		}
+		if got := parenthesise(expr); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.src, got, tt.want)
+		}
+		if printed := ast.String(expr); printed != tt.src {
+			t.Errorf("%s: printed as %s", tt.src, printed)
+		}
+	}
+	// The variable is not bound after the condition
+	if _, err := parser.NewParser(strings.NewReader(`ANY x IN [xs] : x > 1 AND x < 5`)).Parse(); err == nil {
+		t.Error("x bound after the condition")
+	}
+}
+
+// Literal ranges of SATISFIES are parsed once, by the parser
+func TestConstraintLiteral(t *testing.T) {
+	src := `[v] SATISFIES "^1.2 || >=3" AND [v] NOT SATISFIES [range]`
+	expr, err := parser.NewParser(strings.NewReader(src)).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lhs := expr.(*ast.BinaryExpr).LHS.(*ast.BinaryExpr)
+	if c, ok := lhs.RHS.(*ast.ConstraintLiteral); !ok || c.Value.String() != "^1.2 || >=3" {
+		t.Errorf("RHS of SATISFIES = %#v, want a parsed range", lhs.RHS)
+	}
+	if printed := ast.String(expr); printed != src {
+		t.Errorf("printed as %s", printed)
+	}
+	if _, err := parser.NewParser(strings.NewReader(`[v] SATISFIES ">=a"`)).Parse(); err == nil {
+		t.Error("invalid range parsed")
+	}
+}
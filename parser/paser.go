@@ -9,7 +9,9 @@ import (
 	"text/scanner"
 
 	"github.com/thenam153/conditions-go/ast"
+	"github.com/thenam153/conditions-go/cidr"
 	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/semver"
 	"github.com/thenam153/conditions-go/token"
 
 	"github.com/itchyny/gojq"
@@ -27,37 +29,75 @@ type Parser struct {
 	s    scanner.Scanner
 	mbuf mbuffer
 	buf  buffer
+	// Source offsets of scanned tokens
+	start   int
+	end     int
+	prevEnd int
+	tokSpan ast.Span
+	// Why the last ILLEGAL token was scanned, if known
+	scanErr error
+	// An operator is expected, '-' is a subtraction rather than a sign
+	operator bool
+	// Variables bound by the enclosing quantifiers, innermost last
+	bound []string
+	// A token given back by unscanToken, scanned again next
+	pending     bool
+	pendingTok  token.Token
+	pendingTT   string
+	pendingSpan ast.Span
+	// Array literals must not mix element types
+	strictArrays bool
+	// JQ queries are compiled with these variables and options
+	jqVariables []string
+	jqOptions   []gojq.CompilerOption
 }
 
 // Multi-buffer parser
 type mbuffer struct {
-	toks []rune
-	tts  []string
-	fbu  bool // From buffer
+	toks  []rune
+	tts   []string
+	spans []ast.Span
+	fbu   bool // From buffer
 }
 
 // Buffer parser
 type buffer struct {
-	tok rune
-	tt  string
-	fbu bool // From buffer
+	tok  rune
+	tt   string
+	span ast.Span
+	fbu  bool // From buffer
 }
 
-func NewParser(src io.Reader) ParserInterface {
+func NewParser(src io.Reader, opts ...Option) ParserInterface {
 	p := &Parser{s: scanner.Scanner{}, buf: buffer{}}
+	for _, opt := range opts {
+		opt(p)
+	}
 	p.s.Mode = scanner.ScanStrings | scanner.ScanFloats | scanner.ScanIdents
 	p.s.Init(src)
+	// Scanner errors (e.g. unterminated literal) are reported through the
+	// tokens, do not print them to stderr
+	p.s.Error = func(*scanner.Scanner, string) {}
 	return p
 }
 
 func (p *Parser) scan() (rune, string) {
+	var (
+		t    rune
+		tt   string
+		span ast.Span
+	)
 	if SCAN_VERSION == 1 {
-		return p.scannerScan()
+		t, tt, span = p.scannerScan()
+	} else {
+		t, tt, span = p.scannerMScanSingle()
 	}
-	return p.scannerMScanSingle()
+	p.start, p.prevEnd, p.end = span.Start, p.end, span.End
+	return t, tt
 }
 
 func (p *Parser) unscan() {
+	p.end = p.prevEnd
 	if SCAN_VERSION == 1 {
 		p.scannerUnScan()
 	} else {
@@ -65,56 +105,66 @@ func (p *Parser) unscan() {
 	}
 }
 
-func (p *Parser) scannerScan() (rune, string) {
+func (p *Parser) scannerScan() (rune, string, ast.Span) {
 	if !p.buf.fbu {
 		p.buf.tok, p.buf.tt = p.s.Scan(), p.s.TokenText()
+		p.buf.span = p.scannerSpan()
 	} else {
 		p.buf.fbu = false
 	}
-	return p.buf.tok, p.buf.tt
+	return p.buf.tok, p.buf.tt, p.buf.span
+}
+
+// Span of the last token returned by the scanner
+func (p *Parser) scannerSpan() ast.Span {
+	return ast.Span{Start: p.s.Position.Offset, End: p.s.Pos().Offset}
 }
 
 func (p *Parser) scannerUnScan() {
 	p.buf.fbu = true
 }
 
-func (p *Parser) scannerMScan() (rune, string) {
+func (p *Parser) scannerMScan() (rune, string, ast.Span) {
 	if p.mbuf.fbu {
 		if len(p.mbuf.tts) > 0 {
-			t, tt := p.mbuf.toks[0], p.mbuf.tts[0]
-			return t, tt
+			t, tt, span := p.mbuf.toks[0], p.mbuf.tts[0], p.mbuf.spans[0]
+			return t, tt, span
 		}
 	}
 	t, tt := p.s.Scan(), p.s.TokenText()
+	span := p.scannerSpan()
 	p.mbuf.toks, p.mbuf.tts = append(p.mbuf.toks, t), append(p.mbuf.tts, tt)
-	return t, tt
+	p.mbuf.spans = append(p.mbuf.spans, span)
+	return t, tt, span
 }
 
 func (p *Parser) scannerMCommit() {
 	if len(p.mbuf.tts) > 0 {
 		p.mbuf.toks = p.mbuf.toks[1:]
 		p.mbuf.tts = p.mbuf.tts[1:]
+		p.mbuf.spans = p.mbuf.spans[1:]
 	}
 }
 
 func (p *Parser) scannerMCommitAll() {
 	p.mbuf.toks = make([]rune, 0)
 	p.mbuf.tts = make([]string, 0)
+	p.mbuf.spans = make([]ast.Span, 0)
 }
 
 func (p *Parser) scannerMUnScan() {
 	p.mbuf.fbu = true
 }
 
-func (p *Parser) scannerMScanSingle() (rune, string) {
+func (p *Parser) scannerMScanSingle() (rune, string, ast.Span) {
 	if len(p.mbuf.tts) > 1 {
 		p.scannerMCommit()
 	}
-	t, tt := p.scannerMScan()
+	t, tt, span := p.scannerMScan()
 	if p.mbuf.fbu {
 		p.mbuf.fbu = false
 	}
-	return t, tt
+	return t, tt, span
 }
 
 // Scan token from input string reader
@@ -124,8 +174,16 @@ func (p *Parser) scanToken() (token.Token, string) {
 		tt  string
 		tok token.Token
 	)
+	if p.pending {
+		p.pending, p.tokSpan = false, p.pendingSpan
+		return p.pendingTok, p.pendingTT
+	}
 	// Get token and text token
 	t, tt = p.scan()
+	start := p.start
+	defer func() {
+		p.tokSpan = ast.Span{Start: start, End: p.end}
+	}()
 	switch t {
 	case scanner.EOF:
 		tok = token.EOF
@@ -133,20 +191,29 @@ func (p *Parser) scanToken() (token.Token, string) {
 		tok = token.LPAREN
 	case ')':
 		tok = token.RPAREN
+	case ',':
+		tok = token.COMMA
+	case ':':
+		tok = token.COLON
+	case '+':
+		tok = token.ADD
 	case '-':
+		if p.operator {
+			tok = token.SUB
+			break
+		}
 		t, tt = p.scan()
 		if strings.Contains(tt, "-") {
 			tok = token.ILLEGAL
 			break
 		}
 		if t == scanner.Float || t == scanner.Int {
-			tok = token.NUMBER
-			tt = "-" + tt
+			tok, tt = p.scanDuration("-" + tt)
 			break
 		}
 		tok = token.ILLEGAL
 	case scanner.Float, scanner.Int:
-		tok = token.NUMBER
+		tok, tt = p.scanDuration(tt)
 	case '$':
 		var (
 			err  error
@@ -163,6 +230,8 @@ func (p *Parser) scanToken() (token.Token, string) {
 			tt, mode, err = p.scanJQ()
 			if err == nil {
 				tok = token.JQ
+			} else {
+				p.scanErr = err
 			}
 			buildJQMsg := func(tt, mode string) string {
 				jsMsg := ast.JQMsg{
@@ -219,20 +288,34 @@ func (p *Parser) scanToken() (token.Token, string) {
 			tok = token.ILLEGAL
 		}
 	case '/':
+		// Regex is scanned character by character to keep whitespace,
+		// quotes and escaped slashes, e.g. /a b\/"c/
+		tok = token.ILLEGAL
 		for {
-			_t, _tt := p.scan()
-			tt += _tt
-			if _t == '/' {
-				tok = token.STRING
+			ch := p.s.Next()
+			if ch == scanner.EOF {
 				break
 			}
-			if _t == scanner.EOF {
-				tok = token.ILLEGAL
+			tt += string(ch)
+			if ch == '\\' {
+				if ch = p.s.Next(); ch == scanner.EOF {
+					break
+				}
+				tt += string(ch)
+				continue
+			}
+			if ch == '/' {
+				tok = token.STRING
 				break
 			}
 		}
+		p.end = p.s.Pos().Offset
 	case scanner.String:
 		tok = token.STRING
+		if len(tt) < 2 || !strings.HasSuffix(tt, `"`) {
+			// Literal not terminated
+			tok = token.ILLEGAL
+		}
 	case scanner.Ident:
 		ttU := strings.ToUpper(tt)
 		switch ttU {
@@ -246,10 +329,12 @@ func (p *Parser) scanToken() (token.Token, string) {
 			tok = token.XOR
 		case "NOT":
 			_, _tt := p.scan()
+			if op, ok := keywordOperators[strings.ToUpper(_tt)]; ok {
+				tok = p.scanWith(op).Negate()
+				tt = tok.String()
+				break
+			}
 			switch strings.ToUpper(_tt) {
-			case "IN":
-				tt = fmt.Sprintf("%v %v", tt, _tt)
-				tok = token.NOTIN
 			case "TRUE":
 				tt = fmt.Sprintf("%v %v", tt, _tt)
 				tok = token.FALSE
@@ -260,14 +345,58 @@ func (p *Parser) scanToken() (token.Token, string) {
 				p.unscan()
 				tok = token.ILLEGAL
 			}
-		case "IN":
-			tok = token.IN
+		case "IN", "CONTAINS", "ICONTAINS", "STARTS", "ENDS", "LIKE", "ILIKE", "EQI", "SATISFIES",
+			"INTERSECTS", "DISJOINT", "SUBSET", "SUPERSET", "BETWEEN":
+			tok = p.scanWith(keywordOperators[ttU])
+			tt = tok.String()
 		case "TRUE":
 			tok = token.TRUE
 		case "FALSE":
 			tok = token.FALSE
+		case "NULL":
+			tok = token.NULL
+		case "EXISTS":
+			tok = token.EXISTS
+		case "ANY":
+			tok = token.ANY
+		case "ALL":
+			tok = token.ALL
+		case "NONE":
+			tok = token.NONE
+		case "IS":
+			// IS NULL, IS NOT NULL
+			tok = token.ILLEGAL
+			_, _tt := p.scan()
+			not := strings.ToUpper(_tt) == "NOT"
+			if not {
+				_, _tt = p.scan()
+			}
+			if strings.ToUpper(_tt) != "NULL" {
+				break
+			}
+			tt, tok = token.Tokens[token.ISNULL], token.ISNULL
+			if not {
+				tt, tok = token.Tokens[token.ISNOTNULL], token.ISNOTNULL
+			}
 		default:
 			tok = token.ILLEGAL
+			name := tt
+			_t, _tt := p.scan()
+			switch {
+			case name == "t" && _t == scanner.String && p.start == p.prevEnd:
+				// Time literal, t"2026-01-01T00:00:00Z"
+				tt = _tt
+				if len(tt) >= 2 && strings.HasSuffix(tt, `"`) {
+					tok = token.TIME
+				}
+			case p.isBound(name):
+				p.unscan()
+				tok, tt = token.BOUND, name+p.scanPath()
+			case _t == '(':
+				tok = token.FUNC
+			default:
+				p.unscan()
+			}
 		}
 	case '[':
 		tok = token.ILLEGAL
@@ -285,7 +414,7 @@ func (p *Parser) scanToken() (token.Token, string) {
 		}
 		tt += _tt
 	default:
-		tok = token.STRING
+		tok = token.ILLEGAL
 	}
 	return tok, tt
 }
@@ -295,14 +424,20 @@ func (p *Parser) scanArgs() (string, error) {
 		tt  string
 		sep string
 	)
-	// Example: [foo][bar] => foo.bar
+	// Example: [foo][bar] => foo.bar, [@foo] => @foo, [foo][0] => foo.0
+	// Anything else, e.g. ["foo"] or [1], is an array
 	for {
-		_, _tt := p.scan()
-		tt += sep + _tt
-		if tt == "@" {
+		_t, _tt := p.scan()
+		if _t == '@' && tt == "" {
+			tt = _tt
 			continue
 		}
-		_t, _ := p.scan()
+		if _t != scanner.Ident && (_t != scanner.Int || sep == "") {
+			p.unscan()
+			return tt, lerrors.New("Unexpected character, expected argument name")
+		}
+		tt += sep + _tt
+		_t, _ = p.scan()
 		if _t != ']' {
 			p.unscan()
 			return tt, lerrors.New("Unexpected character, missing ']'")
@@ -316,19 +451,31 @@ func (p *Parser) scanArgs() (string, error) {
 	}
 }
 
+// Scan the elements of an array up to its closing ']', nested arrays
+// included. Tokens separated by spaces in the input are separated by one
+// space in the text.
 func (p *Parser) scanArray() (string, error) {
 	var (
-		tt string
+		tt    string
+		depth int
 	)
 	for {
 		_t, _tt := p.scan()
-		if _t == ']' {
-			return tt, nil
-		}
-		if _t == scanner.EOF {
+		switch _t {
+		case '[':
+			depth++
+		case ']':
+			if depth == 0 {
+				return tt, nil
+			}
+			depth--
+		case scanner.EOF:
 			p.unscan()
 			return "", lerrors.New("Unexpected character, missing ']'")
 		}
+		if tt != "" && p.start != p.prevEnd {
+			tt += " "
+		}
 		tt += _tt
 	}
 }
@@ -361,6 +508,9 @@ func (p *Parser) scanJQ() (string, string, error) {
 		if t != ']' {
 			return "", lerrors.New("Unexpected character, missing ']'")
 		}
+		if _, ok := ast.JQModes[mode]; !ok {
+			return "", lerrors.Newf("Unknown JQ mode %q: %w", mode, lerrors.ErrJQ)
+		}
 		return mode, nil
 
 	}
@@ -406,8 +556,10 @@ func (p *Parser) scanJQ() (string, string, error) {
 //	"foo": StringLiteral
 //	"in": Operator
 //	["bar", "baz"]:  SliceStringLiteral
+//	[1, "a", TRUE]:  ListLiteral
 func (p *Parser) parseUnaryExpr() (ast.Expr, error) {
 	tok, lit := p.scanToken()
+	span := p.tokSpan
 	if tok == token.LPAREN {
 		expr, err := p.parseExpr()
 		if err != nil {
@@ -417,57 +569,62 @@ func (p *Parser) parseUnaryExpr() (ast.Expr, error) {
 			return nil, fmt.Errorf("unexpected character, missing )")
 		}
 		return &ast.ParenExpr{
+			Span: ast.Span{Start: span.Start, End: p.tokSpan.End},
 			Expr: expr,
 		}, nil
 	}
 	switch tok {
 	case token.IDENT:
 		return &ast.VarRef{
+			Span:  span,
 			Value: lit,
 		}, nil
 	case token.STRING:
-		return &ast.StringLiteral{Value: lit[1 : len(lit)-1]}, nil
+		return &ast.StringLiteral{Span: span, Value: lit[1 : len(lit)-1]}, nil
 	case token.NUMBER:
 		v, err := strconv.ParseFloat(lit, 64)
 		if err != nil {
 			return nil, lerrors.NewWrap("Cannot convert string to number", err)
 		}
-		return &ast.NumberLiteral{Value: v}, nil
+		return &ast.NumberLiteral{Span: span, Value: v}, nil
 	case token.TRUE, token.FALSE:
-		return &ast.BooleanLiteral{Value: tok == token.TRUE}, nil
-	case token.ARRAY:
-		arrayValue := []any{}
-		if err := json.Unmarshal([]byte("["+lit+"]"), &arrayValue); err != nil {
-			return nil, lerrors.NewWrap("Cannot unmarshal string to array", err)
-		}
-		if len(arrayValue) == 0 {
-			return nil, lerrors.New("Length of array must be greater than 0")
-		}
-		// Get type of first element from array
-		switch t := arrayValue[0].(type) {
-		case string:
-			arrString := make([]string, 0)
-			for _, v := range arrayValue {
-				_v, ok := v.(string)
-				if !ok {
-					continue
-				}
-				arrString = append(arrString, _v)
-			}
-			return &ast.SliceStringLiteral{Value: arrString}, nil
-		case float64:
-			arrNumber := make([]float64, 0)
-			for _, v := range arrayValue {
-				_v, ok := v.(float64)
-				if !ok {
-					continue
-				}
-				arrNumber = append(arrNumber, _v)
-			}
-			return &ast.SliceNumberLiteral{Value: arrNumber}, nil
-		default:
-			return nil, lerrors.Newf("unknown type %s %T", t, t)
+		return &ast.BooleanLiteral{Span: span, Value: tok == token.TRUE}, nil
+	case token.TIME:
+		v, err := ast.ParseTime(lit[1 : len(lit)-1])
+		if err != nil {
+			return nil, lerrors.NewWrap("Cannot convert string to time", err)
 		}
+		return &ast.TimeLiteral{Span: span, Value: v}, nil
+	case token.DURATION:
+		v, err := ast.ParseDuration(lit)
+		if err != nil {
+			return nil, lerrors.NewWrap("Cannot convert string to duration", err)
+		}
+		return &ast.DurationLiteral{Span: span, Value: v}, nil
+	case token.FUNC:
+		return p.parseCall(lit, span)
+	case token.BOUND:
+		name, path, _ := strings.Cut(lit, ".")
+		return &ast.BoundRef{Span: span, Name: name, Path: path}, nil
+	case token.ANY, token.ALL, token.NONE:
+		return p.parseQuantifier(tok, span)
+	case token.NULL:
+		return &ast.NullLiteral{Span: span}, nil
+	case token.EXISTS:
+		operand, err := p.parseUnaryExpr()
+		if err != nil {
+			return nil, lerrors.NewWrap("Cannot parse operand of EXISTS", err)
+		}
+		if _, ok := operand.(*ast.VarRef); !ok {
+			return nil, lerrors.Newf("EXISTS operand must be an argument, got: %v", ast.String(operand))
+		}
+		return &ast.UnaryExpr{
+			Span: ast.Span{Start: span.Start, End: ast.Position(operand).End},
+			OP:   tok,
+			Expr: operand,
+		}, nil
+	case token.ARRAY:
+		return p.parseArray(lit, span)
 	case token.JQ:
 		extractJQMsg := func(msg string) (string, string, error) {
 			jqMsg := ast.JQMsg{}
@@ -482,36 +639,225 @@ func (p *Parser) parseUnaryExpr() (ast.Expr, error) {
 		}
 		query, err := gojq.Parse(qs)
 		if err != nil {
-			return nil, lerrors.NewWrap("Cannot parse string to jq query", err)
+			return nil, lerrors.Wrap(lerrors.Newf("Cannot parse string to jq query: %w", lerrors.ErrJQ), err)
+		}
+		code, err := gojq.Compile(query, append([]gojq.CompilerOption{gojq.WithVariables(p.jqVariables)}, p.jqOptions...)...)
+		if err != nil {
+			return nil, lerrors.Wrap(lerrors.Newf("Cannot compile jq query: %w", lerrors.ErrJQ), err)
 		}
 		return &ast.JQRef{
-			Value: lit,
-			Query: query,
-			Mode:  mode,
+			Span:      span,
+			Value:     lit,
+			Query:     query,
+			Code:      code,
+			Variables: p.jqVariables,
+			Mode:      mode,
 		}, nil
 	default:
+		if tok == token.ILLEGAL && p.scanErr != nil {
+			return nil, lerrors.NewWrap("Cannot scan token", p.scanErr)
+		}
 		return nil, lerrors.Newf("Unknown token type %s, value: %v", tok, lit)
 	}
 }
 
 // Parse expression to get ast.Expr
 func (p *Parser) parseExpr() (ast.Expr, error) {
+	return p.parseOperators(true)
+}
+
+// Parse operators and their operands, with logical false the expression
+// ends before the first logical operator
+func (p *Parser) parseOperators(logical bool) (ast.Expr, error) {
 	expr, err := p.parseUnaryExpr()
 	if err != nil {
 		return nil, lerrors.NewWrap("Cannot parse unary expression", err)
 	}
 	for {
+		p.operator = true
 		op, tt := p.scanToken()
+		p.operator = false
 		if op == token.ILLEGAL {
 			return nil, lerrors.Newf("Must be Operator expression, got: ILLEGAL")
 		}
-		if op == token.EOF || op == token.LPAREN || op == token.RPAREN {
-			p.unscan()
+		if op == token.EOF || op == token.LPAREN || op == token.RPAREN || op == token.COMMA ||
+			(!logical && op.IsLogical()) {
+			p.unscanToken(op, tt)
 			return expr, nil
 		}
+		if op.IsPostfix() {
+			expr = insertPostfix(expr, op, p.tokSpan.End)
+			continue
+		}
 		if !op.IsOperator() {
 			return expr, lerrors.Newf("Must be Operator expression, got: %v", tt)
 		}
+		var rhs ast.Expr
+		if op == token.BETWEEN || op == token.NOTBETWEEN {
+			rhs, err = p.parseRange()
+		} else {
+			rhs, err = p.parseUnaryExpr()
+		}
+		if err != nil {
+			return nil, lerrors.NewWrap("Cannot get unary expression for RHS", err)
+		}
+		if rhs, err = checkLiteral(op, rhs); err != nil {
+			return nil, lerrors.NewWrap("Cannot parse RHS of "+op.String(), err)
+		}
+		expr = insertNode(expr, rhs, op)
+	}
+}
+
+// Give back the last token scanned by scanToken
+func (p *Parser) unscanToken(tok token.Token, tt string) {
+	p.pending, p.pendingTok, p.pendingTT, p.pendingSpan = true, tok, tt, p.tokSpan
+}
+
+// Parse the elements of an array literal. An array of strings or of numbers
+// is a slice literal, any other array, empty ones included, a list literal.
+// A single name in brackets, e.g. [TRUE], is an argument, not an array.
+func (p *Parser) parseArray(lit string, span ast.Span) (ast.Expr, error) {
+	elems := []ast.Expr{}
+	elemParser := NewParser(strings.NewReader(lit)).(*Parser)
+	elemParser.strictArrays = p.strictArrays
+	if tok, tt := elemParser.scanToken(); tok != token.EOF {
+		elemParser.unscanToken(tok, tt)
+		for {
+			elem, err := elemParser.parseElement()
+			if err != nil {
+				return nil, lerrors.NewWrap("Cannot parse element "+strconv.Itoa(len(elems))+" of array", err)
+			}
+			elems = append(elems, elem)
+			tok, tt := elemParser.scanToken()
+			if tok == token.EOF {
+				break
+			}
+			if tok != token.COMMA {
+				return nil, lerrors.Newf("Unexpected token %v in array, expected ','", tt)
+			}
+		}
+	}
+	var (
+		arrString []string
+		arrNumber []float64
+		kind      string
+	)
+	for _, elem := range elems {
+		switch e := elem.(type) {
+		case *ast.StringLiteral:
+			arrString = append(arrString, e.Value)
+		case *ast.NumberLiteral:
+			arrNumber = append(arrNumber, e.Value)
+		}
+		elemKind := elementKind(elem)
+		if p.strictArrays && kind != "" && elemKind != "" && elemKind != kind {
+			return nil, lerrors.Newf("Array mixes %v and %v elements", kind, elemKind)
+		}
+		if kind == "" {
+			kind = elemKind
+		}
+	}
+	switch {
+	case len(elems) > 0 && len(arrString) == len(elems):
+		return &ast.SliceStringLiteral{Span: span, Value: arrString}, nil
+	case len(elems) > 0 && len(arrNumber) == len(elems):
+		return &ast.SliceNumberLiteral{Span: span, Value: arrNumber}, nil
+	}
+	return &ast.ListLiteral{Span: span, Value: elems}, nil
+}
+
+// Parse an array element, a literal or a nested array. Strings are JSON
+// strings.
+func (p *Parser) parseElement() (ast.Expr, error) {
+	elem, err := p.parseUnaryExpr()
+	if err != nil {
+		return nil, err
+	}
+	switch e := elem.(type) {
+	case *ast.StringLiteral:
+		var v string
+		if err := json.Unmarshal([]byte(`"`+e.Value+`"`), &v); err != nil {
+			return nil, lerrors.NewWrap("Cannot unmarshal string of array", err)
+		}
+		e.Value = v
+	case *ast.NumberLiteral, *ast.BooleanLiteral, *ast.NullLiteral, *ast.TimeLiteral, *ast.DurationLiteral,
+		*ast.SliceStringLiteral, *ast.SliceNumberLiteral, *ast.ListLiteral:
+	default:
+		return nil, lerrors.Newf("Unexpected element %v, expected a literal", ast.String(elem))
+	}
+	return elem, nil
+}
+
+// Kind of an array element for WithStrictArrays, NULL fits any kind
+func elementKind(e ast.Expr) string {
+	switch e.(type) {
+	case *ast.StringLiteral:
+		return "string"
+	case *ast.NumberLiteral:
+		return "number"
+	case *ast.BooleanLiteral:
+		return "boolean"
+	case *ast.TimeLiteral:
+		return "time"
+	case *ast.DurationLiteral:
+		return "duration"
+	case *ast.NullLiteral:
+		return ""
+	}
+	return "array"
+}
+
+// Parse the bounds of BETWEEN: low AND high, optionally followed by
+// INCLUSIVE (the default) or EXCLUSIVE. The AND is the separator, not the
+// logical operator.
+func (p *Parser) parseRange() (ast.Expr, error) {
+	low, err := p.parseBound()
+	if err != nil {
+		return nil, lerrors.NewWrap("Cannot parse lower bound of BETWEEN", err)
+	}
+	p.operator = true
+	tok, tt := p.scanToken()
+	p.operator = false
+	if tok != token.AND {
+		return nil, lerrors.Newf("Unexpected token %v, expected AND after lower bound of BETWEEN", tt)
+	}
+	high, err := p.parseBound()
+	if err != nil {
+		return nil, lerrors.NewWrap("Cannot parse upper bound of BETWEEN", err)
+	}
+	rng := &ast.RangeExpr{
+		Span: ast.Span{Start: ast.Position(low).Start, End: ast.Position(high).End},
+		Low:  low,
+		High: high,
+	}
+	p.operator = true
+	tok, tt = p.scanToken()
+	p.operator = false
+	switch strings.ToUpper(tt) {
+	case "EXCLUSIVE", "INCLUSIVE":
+		if tok == token.ILLEGAL {
+			rng.Exclusive, rng.End = strings.ToUpper(tt) == "EXCLUSIVE", p.tokSpan.End
+			return rng, nil
+		}
+	}
+	p.unscanToken(tok, tt)
+	return rng, nil
+}
+
+// Parse a bound of BETWEEN, arithmetic binds tighter than the AND after it
+func (p *Parser) parseBound() (ast.Expr, error) {
+	expr, err := p.parseUnaryExpr()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.operator = true
+		op, tt := p.scanToken()
+		p.operator = false
+		if !op.IsArithmetic() {
+			p.unscanToken(op, tt)
+			return expr, nil
+		}
 		rhs, err := p.parseUnaryExpr()
 		if err != nil {
 			return nil, lerrors.NewWrap("Cannot get unary expression for RHS", err)
@@ -520,6 +866,42 @@ func (p *Parser) parseExpr() (ast.Expr, error) {
 	}
 }
 
+// Version ranges and CIDR prefixes are parsed once here rather than on every
+// evaluation
+func checkLiteral(op token.Token, rhs ast.Expr) (ast.Expr, error) {
+	switch op {
+	case token.SATISFIES, token.NOTSATISFIES:
+		if s, ok := rhs.(*ast.StringLiteral); ok {
+			c, err := semver.ParseConstraint(s.Value)
+			if err != nil {
+				return nil, err
+			}
+			return &ast.ConstraintLiteral{Span: s.Span, Value: c}, nil
+		}
+	case token.INCIDR, token.NOTINCIDR:
+		var prefixes []string
+		switch e := rhs.(type) {
+		case *ast.StringLiteral:
+			prefixes = []string{e.Value}
+		case *ast.SliceStringLiteral:
+			prefixes = e.Value
+		case *ast.ListLiteral:
+			// Only the empty list, of no prefixes
+			if len(e.Value) > 0 {
+				return rhs, nil
+			}
+		default:
+			return rhs, nil
+		}
+		set, err := cidr.Parse(prefixes...)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.CIDRLiteral{Span: ast.Position(rhs), Value: set}, nil
+	}
+	return rhs, nil
+}
+
 // Compare priority of operator to insert node into ast
 func insertNode(l, r ast.Expr, op token.Token) ast.Expr {
 	var (
@@ -529,20 +911,228 @@ func insertNode(l, r ast.Expr, op token.Token) ast.Expr {
 	if ok {
 		if lhs.OP.Precedence() < op.Precedence() {
 			return &ast.BinaryExpr{
-				LHS: lhs.LHS,
-				RHS: insertNode(lhs.RHS, r, op),
-				OP:  lhs.OP,
+				Span: ast.Span{Start: lhs.Start, End: ast.Position(r).End},
+				LHS:  lhs.LHS,
+				RHS:  insertNode(lhs.RHS, r, op),
+				OP:   lhs.OP,
 			}
 		}
 	}
 	expr = &ast.BinaryExpr{
-		LHS: l,
-		OP:  op,
-		RHS: r,
+		Span: ast.Span{Start: ast.Position(l).Start, End: ast.Position(r).End},
+		LHS:  l,
+		OP:   op,
+		RHS:  r,
 	}
 	return expr
 }
 
+// Reserved words, they cannot name a quantifier variable
+var keywords = map[string]bool{
+	"AND": true, "NAND": true, "OR": true, "XOR": true, "NOT": true, "TRUE": true, "FALSE": true,
+	"NULL": true, "IS": true, "EXISTS": true, "ANY": true, "ALL": true, "NONE": true,
+}
+
+// Keyword operators, their first word
+var keywordOperators = map[string]token.Token{
+	"IN":         token.IN,
+	"CONTAINS":   token.CONTAINS,
+	"ICONTAINS":  token.ICONTAINS,
+	"STARTS":     token.STARTSWITH,
+	"ENDS":       token.ENDSWITH,
+	"LIKE":       token.LIKE,
+	"ILIKE":      token.ILIKE,
+	"EQI":        token.EQI,
+	"SATISFIES":  token.SATISFIES,
+	"INTERSECTS": token.INTERSECTS,
+	"DISJOINT":   token.DISJOINT,
+	"SUBSET":     token.SUBSETOF,
+	"SUPERSET":   token.SUPERSETOF,
+	"BETWEEN":    token.BETWEEN,
+}
+
+// Second word of two word operators, e.g. STARTS WITH
+var operatorSuffix = map[token.Token]string{
+	token.STARTSWITH: "WITH",
+	token.ENDSWITH:   "WITH",
+	token.SUBSETOF:   "OF",
+	token.SUPERSETOF: "OF",
+}
+
+func (p *Parser) isBound(name string) bool {
+	for _, v := range p.bound {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan the path after a bound variable, e.g. ".items.0.qty"
+func (p *Parser) scanPath() string {
+	path := ""
+	for {
+		t, tt := p.scan()
+		switch {
+		case t == '.' && p.start == p.prevEnd:
+			t, tt = p.scan()
+			if (t != scanner.Ident && t != scanner.Int) || p.start != p.prevEnd {
+				p.unscan()
+				return path
+			}
+			path += "." + tt
+		case t == scanner.Float && p.start == p.prevEnd && isIndexPath(tt):
+			// The scanner reads ".0" as a number
+			path += tt
+		default:
+			p.unscan()
+			return path
+		}
+	}
+}
+
+// Report whether s is a path of indices, e.g. ".0" or ".0.1"
+func isIndexPath(s string) bool {
+	for _, segment := range strings.Split(s, ".")[1:] {
+		if _, err := strconv.Atoi(segment); err != nil {
+			return false
+		}
+	}
+	return strings.HasPrefix(s, ".") && len(s) > 1
+}
+
+// Parse a quantifier after ANY, ALL or NONE: x IN [items] : x.qty > 0. The
+// condition binds like a comparison, it ends before the first AND, NAND, OR
+// or XOR: ANY x IN [xs] : x > 1 AND [flag] is (ANY x IN [xs] : x > 1) AND
+// [flag]. A condition with logical operators is parenthesised.
+func (p *Parser) parseQuantifier(op token.Token, span ast.Span) (ast.Expr, error) {
+	t, name := p.scan()
+	if _, ok := keywordOperators[strings.ToUpper(name)]; t != scanner.Ident || ok || keywords[strings.ToUpper(name)] {
+		return nil, lerrors.Newf("Unexpected token %v, expected variable name after %v", name, op)
+	}
+	if tok, tt := p.scanToken(); tok != token.IN {
+		return nil, lerrors.Newf("Unexpected token %v, expected IN after %v %v", tt, op, name)
+	}
+	source, err := p.parseUnaryExpr()
+	if err != nil {
+		return nil, lerrors.NewWrap("Cannot parse list of "+op.String(), err)
+	}
+	if tok, tt := p.scanToken(); tok != token.COLON {
+		return nil, lerrors.Newf("Unexpected token %v, expected ':' after list of %v", tt, op)
+	}
+	p.bound = append(p.bound, name)
+	body, err := p.parseOperators(false)
+	p.bound = p.bound[:len(p.bound)-1]
+	if err != nil {
+		return nil, lerrors.NewWrap("Cannot parse condition of "+op.String(), err)
+	}
+	return &ast.QuantifierExpr{
+		Span:   ast.Span{Start: span.Start, End: ast.Position(body).End},
+		OP:     op,
+		Var:    name,
+		Source: source,
+		Body:   body,
+	}, nil
+}
+
+// A number directly followed by a unit is a duration, e.g. 7d or 1h30m
+func (p *Parser) scanDuration(number string) (token.Token, string) {
+	t, tt := p.scan()
+	if t == scanner.Ident && p.start == p.prevEnd {
+		if _, err := ast.ParseDuration(number + tt); err == nil {
+			return token.DURATION, number + tt
+		}
+	}
+	p.unscan()
+	return token.NUMBER, number
+}
+
+// Parse the arguments of a function call, the opening parenthesis is scanned
+func (p *Parser) parseCall(name string, span ast.Span) (ast.Expr, error) {
+	arity, ok := ast.Functions[name]
+	if !ok {
+		return nil, lerrors.Newf("Unknown function %v", name)
+	}
+	call := &ast.CallExpr{Span: span, Name: name, Args: []ast.Expr{}}
+	if t, _ := p.scan(); t != ')' {
+		p.unscan()
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, lerrors.NewWrap("Cannot parse argument of "+name, err)
+			}
+			call.Args = append(call.Args, arg)
+			tok, _ := p.scanToken()
+			if tok == token.RPAREN {
+				break
+			}
+			if tok != token.COMMA {
+				return nil, lerrors.Newf("Unexpected character, missing ')' after arguments of %v", name)
+			}
+		}
+	}
+	call.End = p.end
+	if len(call.Args) != arity {
+		return nil, lerrors.Newf("Function %v takes %d arguments, got %d", name, arity, len(call.Args))
+	}
+	return call, nil
+}
+
+// Scan the second word of two word operators, e.g. the WITH of STARTS WITH,
+// and the optional CIDR of IN CIDR
+func (p *Parser) scanWith(op token.Token) token.Token {
+	if op == token.IN {
+		if _, tt := p.scan(); strings.ToUpper(tt) == "CIDR" {
+			return token.INCIDR
+		}
+		p.unscan()
+		return op
+	}
+	suffix, ok := operatorSuffix[op]
+	if !ok {
+		return op
+	}
+	if _, tt := p.scan(); strings.ToUpper(tt) != suffix {
+		return token.ILLEGAL
+	}
+	return op
+}
+
+// Postfix operators bind tightest, they apply to the rightmost operand
+func insertPostfix(e ast.Expr, op token.Token, end int) ast.Expr {
+	if b, ok := e.(*ast.BinaryExpr); ok {
+		return &ast.BinaryExpr{
+			Span: ast.Span{Start: b.Start, End: end},
+			LHS:  b.LHS,
+			RHS:  insertPostfix(b.RHS, op, end),
+			OP:   b.OP,
+		}
+	}
+	return &ast.UnaryExpr{
+		Span: ast.Span{Start: ast.Position(e).Start, End: end},
+		OP:   op,
+		Expr: e,
+	}
+}
+
+// Parse returns the expression of the whole input, errors are *errors.ParseError
 func (p *Parser) Parse() (ast.Expr, error) {
-	return p.parseExpr()
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, p.parseError(err)
+	}
+	if tok, tt := p.scanToken(); tok != token.EOF {
+		return nil, p.parseError(lerrors.Newf("Unexpected token %v after expression", tt))
+	}
+	return expr, nil
+}
+
+// Locate err at the last token read from the input
+func (p *Parser) parseError(err error) error {
+	return &lerrors.ParseError{
+		Offset: p.s.Position.Offset,
+		Line:   p.s.Position.Line,
+		Column: p.s.Position.Column,
+		Err:    err,
+	}
 }
@@ -0,0 +1,230 @@
+package semver
+
+import (
+	"regexp"
+	"strings"
+
+	lerrors "github.com/thenam153/conditions-go/errors"
+)
+
+// Constraint is a version range in npm syntax: comparators (<, <=, >, >=,
+// =) of partial versions, x wildcards, ~ and ^ ranges and hyphen ranges
+// "1.2 - 2.0", separated by spaces or commas to intersect and by || to unite.
+type Constraint struct {
+	src  string
+	sets [][]comparator
+}
+
+type comparator struct {
+	op string
+	v  Version
+}
+
+var (
+	hyphenRange = regexp.MustCompile(`^(\S+)\s+-\s+(\S+)$`)
+	// Spaces between an operator and its version, e.g. ">= 1.2"
+	operatorSpace = regexp.MustCompile(`(>=|<=|>|<|=|\^|~)\s+`)
+)
+
+// ParseConstraint parses a version range, e.g. "^2.3 || >=3.1.0"
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{src: s}
+	for _, rng := range strings.Split(s, "||") {
+		set, err := parseRange(strings.TrimSpace(rng))
+		if err != nil {
+			return nil, lerrors.NewWrap("Invalid version range "+s, err)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+func parseRange(s string) ([]comparator, error) {
+	if m := hyphenRange.FindStringSubmatch(s); m != nil {
+		from, err := parsePartial(m[1])
+		if err != nil {
+			return nil, err
+		}
+		to, err := parsePartial(m[2])
+		if err != nil {
+			return nil, err
+		}
+		set := []comparator{{">=", from.Version}}
+		if to.n == 3 {
+			return append(set, comparator{"<=", to.Version}), nil
+		}
+		return append(set, upper(to)...), nil
+	}
+	fields := strings.FieldsFunc(operatorSpace.ReplaceAllString(s, "$1"), func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	})
+	if len(fields) == 0 {
+		// An empty range matches every version
+		return []comparator{{">=", Version{}}}, nil
+	}
+	set := []comparator{}
+	for _, field := range fields {
+		comparators, err := parseComparator(field)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, comparators...)
+	}
+	return set, nil
+}
+
+// Desugar one comparator of a range to primitive comparators
+func parseComparator(s string) ([]comparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(s, prefix) {
+			op, s = prefix, s[len(prefix):]
+			break
+		}
+	}
+	p, err := parsePartial(s)
+	if err != nil {
+		return nil, err
+	}
+	lower := comparator{">=", p.Version}
+	switch op {
+	case "", "=":
+		if p.n == 3 {
+			return []comparator{{"=", p.Version}}, nil
+		}
+		return append([]comparator{lower}, upper(p)...), nil
+	case ">=":
+		return []comparator{lower}, nil
+	case ">":
+		if p.n == 3 {
+			return []comparator{{">", p.Version}}, nil
+		}
+		if p.n == 0 {
+			// Nothing is greater than every version
+			return []comparator{{"<", Version{Pre: []string{"0"}}}}, nil
+		}
+		return []comparator{{">=", bump(p, p.n-1)}}, nil
+	case "<":
+		return []comparator{{"<", floor(p.Version)}}, nil
+	case "<=":
+		if p.n == 3 {
+			return []comparator{{"<=", p.Version}}, nil
+		}
+		return upper(p), nil
+	case "~":
+		// Patch updates, minor ones when only the major is given
+		if p.n <= 1 {
+			return append([]comparator{lower}, upper(p)...), nil
+		}
+		return []comparator{lower, {"<", floor(bump(p, 1))}}, nil
+	default:
+		// ^: updates not changing the leftmost non-zero number
+		switch {
+		case p.n == 0:
+			return []comparator{lower}, nil
+		case p.Major > 0 || p.n == 1:
+			return []comparator{lower, {"<", floor(bump(p, 0))}}, nil
+		case p.Minor > 0 || p.n == 2:
+			return []comparator{lower, {"<", floor(bump(p, 1))}}, nil
+		}
+		return []comparator{lower, {"<", floor(bump(p, 2))}}, nil
+	}
+}
+
+// Exclusive upper bound of the versions a partial version stands for
+func upper(p partial) []comparator {
+	if p.n == 0 {
+		return nil
+	}
+	return []comparator{{"<", floor(bump(p, p.n-1))}}
+}
+
+// Increment number i of p, zeroing the following ones
+func bump(p partial, i int) Version {
+	v := Version{Major: p.Major, Minor: p.Minor, Patch: p.Patch}
+	switch i {
+	case 0:
+		v = Version{Major: v.Major + 1}
+	case 1:
+		v = Version{Major: v.Major, Minor: v.Minor + 1}
+	default:
+		v.Patch++
+	}
+	return v
+}
+
+// The lowest version of a release, its first pre-release, so that an upper
+// bound excludes the pre-releases of the next version
+func floor(v Version) Version {
+	if len(v.Pre) > 0 {
+		return v
+	}
+	v.Pre, v.Build = []string{"0"}, ""
+	return v
+}
+
+// Check reports whether v is in the range. A pre-release is only in a range
+// having a comparator with a pre-release of the same MAJOR.MINOR.PATCH, as in
+// npm.
+func (c *Constraint) Check(v Version) bool {
+	for _, set := range c.sets {
+		if checkSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkSet(set []comparator, v Version) bool {
+	for _, cmp := range set {
+		if !cmp.check(v) {
+			return false
+		}
+	}
+	if len(v.Pre) == 0 {
+		return true
+	}
+	for _, cmp := range set {
+		// Bounds added by desugaring are not explicit pre-releases
+		if len(cmp.v.Pre) > 0 && !isFloor(cmp) &&
+			cmp.v.Major == v.Major && cmp.v.Minor == v.Minor && cmp.v.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+func isFloor(cmp comparator) bool {
+	return cmp.op == "<" && len(cmp.v.Pre) == 1 && cmp.v.Pre[0] == "0"
+}
+
+func (cmp comparator) check(v Version) bool {
+	c := v.Compare(cmp.v)
+	switch cmp.op {
+	case "=":
+		return c == 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// Versions returns the versions bounding the range, e.g. to pick versions
+// inside and outside of it
+func (c *Constraint) Versions() []Version {
+	var versions []Version
+	for _, set := range c.sets {
+		for _, cmp := range set {
+			versions = append(versions, cmp.v)
+		}
+	}
+	return versions
+}
+
+func (c *Constraint) String() string {
+	return c.src
+}
@@ -0,0 +1,180 @@
+// Package semver parses and orders semantic versions (https://semver.org)
+// and checks them against npm style ranges, e.g. "^2.3 || >=3.1.0".
+package semver
+
+import (
+	"strconv"
+	"strings"
+
+	lerrors "github.com/thenam153/conditions-go/errors"
+)
+
+// Version is a semantic version, Build is ignored by ordering and equality
+type Version struct {
+	Major uint64
+	Minor uint64
+	Patch uint64
+	Pre   []string
+	Build string
+}
+
+// Parse parses a full version MAJOR.MINOR.PATCH[-PRE][+BUILD], a leading
+// "v" is allowed
+func Parse(s string) (Version, error) {
+	p, err := parsePartial(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if p.n < 3 {
+		return Version{}, lerrors.Newf("Invalid version %q, expected MAJOR.MINOR.PATCH", s)
+	}
+	return p.Version, nil
+}
+
+// Compare returns -1, 0 or 1 as v is lower than, equal to or greater than
+// o, a pre-release is lower than its release
+func (v Version) Compare(o Version) int {
+	for _, c := range [][2]uint64{{v.Major, o.Major}, {v.Minor, o.Minor}, {v.Patch, o.Patch}} {
+		if c[0] != c[1] {
+			if c[0] < c[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(v.Pre) == 0 && len(o.Pre) == 0:
+		return 0
+	case len(v.Pre) == 0:
+		return 1
+	case len(o.Pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Pre) && i < len(o.Pre); i++ {
+		if c := comparePre(v.Pre[i], o.Pre[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(v.Pre) < len(o.Pre):
+		return -1
+	case len(v.Pre) > len(o.Pre):
+		return 1
+	}
+	return 0
+}
+
+// Numeric identifiers are ordered by value and before alphanumeric ones
+func comparePre(a, b string) int {
+	an, aerr := strconv.ParseUint(a, 10, 64)
+	bn, berr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aerr == nil && berr == nil:
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+		return 0
+	case aerr == nil:
+		return -1
+	case berr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func (v Version) String() string {
+	s := strconv.FormatUint(v.Major, 10) + "." + strconv.FormatUint(v.Minor, 10) + "." + strconv.FormatUint(v.Patch, 10)
+	if len(v.Pre) > 0 {
+		s += "-" + strings.Join(v.Pre, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// MarshalText encodes v as its string, e.g. for JQ queries
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// A version whose minor or patch may be missing or a wildcard (x, X, *),
+// n counts the given numbers
+type partial struct {
+	Version
+	n int
+}
+
+func parsePartial(s string) (partial, error) {
+	var p partial
+	rest := strings.TrimPrefix(strings.TrimPrefix(s, "v"), "=")
+	if i := strings.IndexByte(rest, '+'); i >= 0 {
+		p.Build, rest = rest[i+1:], rest[:i]
+		if err := validIdentifiers(p.Build, false); err != nil {
+			return p, lerrors.NewWrap("Invalid build metadata of "+strconv.Quote(s), err)
+		}
+	}
+	if i := strings.IndexByte(rest, '-'); i >= 0 {
+		pre := rest[i+1:]
+		rest = rest[:i]
+		if err := validIdentifiers(pre, true); err != nil {
+			return p, lerrors.NewWrap("Invalid pre-release of "+strconv.Quote(s), err)
+		}
+		p.Pre = strings.Split(pre, ".")
+	}
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 || rest == "" {
+		return p, lerrors.Newf("Invalid version %q", s)
+	}
+	nums := []*uint64{&p.Major, &p.Minor, &p.Patch}
+	wildcard := false
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			wildcard = true
+			continue
+		}
+		if wildcard {
+			return p, lerrors.Newf("Invalid version %q, number after wildcard", s)
+		}
+		if len(part) > 1 && part[0] == '0' {
+			return p, lerrors.Newf("Invalid version %q, leading zero", s)
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return p, lerrors.NewWrap("Invalid version "+strconv.Quote(s), err)
+		}
+		*nums[i] = n
+		p.n++
+	}
+	if (len(p.Pre) > 0 || p.Build != "") && p.n < 3 {
+		return p, lerrors.Newf("Invalid version %q, pre-release of a partial version", s)
+	}
+	return p, nil
+}
+
+// Dot separated identifiers of [0-9A-Za-z-], numeric pre-release
+// identifiers have no leading zero
+func validIdentifiers(s string, pre bool) error {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return lerrors.New("Empty identifier")
+		}
+		numeric := true
+		for _, r := range id {
+			switch {
+			case r >= '0' && r <= '9':
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-':
+				numeric = false
+			default:
+				return lerrors.Newf("Invalid character %q in identifier %q", r, id)
+			}
+		}
+		if pre && numeric && len(id) > 1 && id[0] == '0' {
+			return lerrors.Newf("Leading zero in identifier %q", id)
+		}
+	}
+	return nil
+}
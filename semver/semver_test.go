@@ -0,0 +1,142 @@
+package semver_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thenam153/conditions-go/semver"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want semver.Version
+		err  bool
+	}{
+		{in: "1.2.3", want: semver.Version{Major: 1, Minor: 2, Patch: 3}},
+		{in: "v0.0.0", want: semver.Version{}},
+		{in: "1.2.3-alpha.1", want: semver.Version{Major: 1, Minor: 2, Patch: 3, Pre: []string{"alpha", "1"}}},
+		{in: "1.2.3+build.5", want: semver.Version{Major: 1, Minor: 2, Patch: 3, Build: "build.5"}},
+		{in: "1.2.3-rc-1+001", want: semver.Version{Major: 1, Minor: 2, Patch: 3, Pre: []string{"rc-1"}, Build: "001"}},
+		{in: "1.2", err: true},
+		{in: "1.2.x", err: true},
+		{in: "1.2.3.4", err: true},
+		{in: "01.2.3", err: true},
+		{in: "1.2.3-01", err: true},
+		{in: "1.2.3-", err: true},
+		{in: "1.2.3+", err: true},
+		{in: "1.2.3-a..b", err: true},
+		{in: "1.2.3-a_b", err: true},
+		{in: "a.b.c", err: true},
+		{in: "", err: true},
+	}
+	for _, tt := range tests {
+		got, err := semver.Parse(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("Parse(%q): got error %v, want error %v", tt.in, err, tt.err)
+			continue
+		}
+		if !tt.err && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Ascending, from the semver specification
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "1.10.0", "2.0.0-0", "2.0.0",
+	}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			if got := mustParse(t, a).Compare(mustParse(t, b)); got != want {
+				t.Errorf("%v.Compare(%v) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+	// Build metadata is ignored
+	if got := mustParse(t, "1.0.0+a").Compare(mustParse(t, "1.0.0+b")); got != 0 {
+		t.Errorf("build metadata compared: %d", got)
+	}
+	if got := mustParse(t, "1.0.0-rc.1+a").String(); got != "1.0.0-rc.1+a" {
+		t.Errorf("String() = %v", got)
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		rng  string
+		in   []string
+		out  []string
+		fail bool
+	}{
+		{rng: "^0.0.3", in: []string{"0.0.3"}, out: []string{"0.0.2", "0.0.4", "0.1.0"}},
+		{rng: "^0.0.x", in: []string{"0.0.0", "0.0.9"}, out: []string{"0.1.0", "0.1.0-0"}},
+		{rng: "^0.0", in: []string{"0.0.9"}, out: []string{"0.1.0"}},
+		{rng: "^0.x", in: []string{"0.0.0", "0.9.9"}, out: []string{"1.0.0", "1.0.0-rc.1"}},
+		{rng: "^0.2.3", in: []string{"0.2.3", "0.2.9"}, out: []string{"0.2.2", "0.3.0"}},
+		{rng: "^1.2", in: []string{"1.2.0", "1.9.9"}, out: []string{"1.1.9", "2.0.0", "2.0.0-0"}},
+		{rng: "~1", in: []string{"1.0.0", "1.9.9"}, out: []string{"0.9.9", "2.0.0"}},
+		{rng: "~1.2", in: []string{"1.2.0", "1.2.9"}, out: []string{"1.3.0"}},
+		{rng: "~1.2.3", in: []string{"1.2.3", "1.2.9"}, out: []string{"1.2.2", "1.3.0"}},
+		// Partial upper bounds of hyphen ranges are exclusive of the next release
+		{rng: "1.2 - 2.3", in: []string{"1.2.0", "2.3.9"}, out: []string{"1.1.9", "2.4.0", "2.4.0-0"}},
+		{rng: "1.2.3 - 2", in: []string{"1.2.3", "2.9.9"}, out: []string{"1.2.2", "3.0.0"}},
+		{rng: "1.2.3 - 2.3.4", in: []string{"2.3.4"}, out: []string{"2.3.5"}},
+		{rng: "<1.0.0 || >=2.1", in: []string{"0.9.0", "2.1.0", "3.0.0"}, out: []string{"1.5.0", "2.0.9"}},
+		{rng: ">=1.2.7 <1.3.0 || 2.x", in: []string{"1.2.7", "2.5.0"}, out: []string{"1.3.0", "3.0.0"}},
+		{rng: ">= 1.2, < 2", in: []string{"1.2.0", "1.9.9"}, out: []string{"1.1.0", "2.0.0"}},
+		{rng: ">*", out: []string{"0.0.0", "1.0.0", "99.0.0"}},
+		{rng: "*", in: []string{"0.0.0", "1.0.0"}, out: []string{"1.0.0-rc.1"}},
+		{rng: "", in: []string{"1.0.0"}, out: []string{"1.0.0-rc.1"}},
+		{rng: ">1.2", in: []string{"1.3.0"}, out: []string{"1.2.9"}},
+		{rng: "<=1.2", in: []string{"1.2.9"}, out: []string{"1.3.0"}},
+		{rng: "=1.2.3", in: []string{"1.2.3", "1.2.3+build"}, out: []string{"1.2.4"}},
+		// Pre-releases only match a comparator with a pre-release of the same
+		// MAJOR.MINOR.PATCH
+		{rng: ">1.2.3-alpha.3", in: []string{"1.2.3-alpha.7", "3.4.5"}, out: []string{"1.2.3-alpha.2", "3.4.5-alpha.9"}},
+		{rng: "^1.2.3-beta.2", in: []string{"1.2.3-beta.4", "1.2.3", "1.9.0"}, out: []string{"1.2.4-beta.2", "2.0.0-0"}},
+		{rng: "<2.0.0", in: []string{"1.9.9"}, out: []string{"2.0.0-rc.1", "1.9.9-rc.1"}},
+		{rng: "^x.1", fail: true},
+		{rng: ">=a", fail: true},
+		{rng: "1.2.3 -", fail: true},
+		{rng: "^1.2 || >=01.0", fail: true},
+	}
+	for _, tt := range tests {
+		c, err := semver.ParseConstraint(tt.rng)
+		if (err != nil) != tt.fail {
+			t.Errorf("ParseConstraint(%q): got error %v, want error %v", tt.rng, err, tt.fail)
+			continue
+		}
+		if tt.fail {
+			continue
+		}
+		for _, v := range tt.in {
+			if !c.Check(mustParse(t, v)) {
+				t.Errorf("%v not in %q", v, tt.rng)
+			}
+		}
+		for _, v := range tt.out {
+			if c.Check(mustParse(t, v)) {
+				t.Errorf("%v in %q", v, tt.rng)
+			}
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) semver.Version {
+	t.Helper()
+	v, err := semver.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
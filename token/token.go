@@ -31,6 +31,11 @@ var (
 			End:   operatorEndLevel3,
 			Value: 3,
 		},
+		{
+			Begin: operatorBeginLevel4,
+			End:   operatorEndLevel4,
+			Value: 4,
+		},
 	}
 )
 
@@ -49,12 +54,31 @@ const (
 	ARRAY
 	TRUE
 	FALSE
+	NULL
+	TIME     // t"2026-01-01T00:00:00Z"
+	DURATION // 7d, 1h30m
+	BOUND    // x.qty, an element bound by a quantifier
 	literalEnd
 
 	funcBegin
 	JQ
+	FUNC // now(), hour(...)
 	funcEnd
 
+	// Unary operators, EXISTS is prefix, IS NULL and IS NOT NULL are postfix
+	unaryBegin
+	EXISTS
+	ISNULL
+	ISNOTNULL
+	unaryEnd
+
+	// Quantifiers, ANY x IN [items] : x.qty > 0
+	quantifierBegin
+	ANY
+	ALL
+	NONE
+	quantifierEnd
+
 	// Begin token represent operator
 	operatorBegin
 	operatorBeginLevel1
@@ -78,26 +102,72 @@ const (
 	NEREG // !~
 	IN
 	NOTIN
-	operatorEnd
+	CONTAINS
+	NOTCONTAINS
+	ICONTAINS
+	NOTICONTAINS
+	STARTSWITH
+	NOTSTARTSWITH
+	ENDSWITH
+	NOTENDSWITH
+	LIKE
+	NOTLIKE
+	ILIKE
+	NOTILIKE
+	EQI
+	NOTEQI
+	SATISFIES
+	NOTSATISFIES
+	INCIDR
+	NOTINCIDR
+	INTERSECTS
+	DISJOINT
+	SUBSETOF
+	NOTSUBSETOF
+	SUPERSETOF
+	NOTSUPERSETOF
+	BETWEEN
+	NOTBETWEEN
 	operatorEndLevel3
+
+	operatorBeginLevel4
+	ADD // +
+	SUB // -
+	operatorEndLevel4
+	operatorEnd
 	// End token represent operator
 
 	LPAREN // (
 	RPAREN // )
+	COMMA  // ,
+	COLON  // :
 )
 
 var Tokens = [...]string{
 	ILLEGAL: "ILLEGAL",
 	EOF:     "EOF",
 
-	IDENT:  "IDENT",
-	NUMBER: "NUMBER",
-	STRING: "STRING",
-	ARRAY:  "ARRAY",
-	TRUE:   "TRUE",
-	FALSE:  "FALSE",
+	IDENT:    "IDENT",
+	NUMBER:   "NUMBER",
+	STRING:   "STRING",
+	ARRAY:    "ARRAY",
+	TRUE:     "TRUE",
+	FALSE:    "FALSE",
+	NULL:     "NULL",
+	TIME:     "TIME",
+	DURATION: "DURATION",
+	BOUND:    "BOUND",
 
-	JQ: "JQ",
+	JQ:   "JQ",
+	FUNC: "FUNC",
+
+	EXISTS:    "EXISTS",
+	ISNULL:    "IS NULL",
+	ISNOTNULL: "IS NOT NULL",
+
+	ANY:  "ANY",
+	ALL:  "ALL",
+	NONE: "NONE",
 
 	OR:  "OR",
 	XOR: "XOR",
@@ -117,8 +187,40 @@ var Tokens = [...]string{
 	IN:    "IN",
 	NOTIN: "NOT IN",
 
+	CONTAINS:      "CONTAINS",
+	NOTCONTAINS:   "NOT CONTAINS",
+	ICONTAINS:     "ICONTAINS",
+	NOTICONTAINS:  "NOT ICONTAINS",
+	STARTSWITH:    "STARTS WITH",
+	NOTSTARTSWITH: "NOT STARTS WITH",
+	ENDSWITH:      "ENDS WITH",
+	NOTENDSWITH:   "NOT ENDS WITH",
+	LIKE:          "LIKE",
+	NOTLIKE:       "NOT LIKE",
+	ILIKE:         "ILIKE",
+	NOTILIKE:      "NOT ILIKE",
+	EQI:           "EQI",
+	NOTEQI:        "NOT EQI",
+	SATISFIES:     "SATISFIES",
+	NOTSATISFIES:  "NOT SATISFIES",
+	INCIDR:        "IN CIDR",
+	NOTINCIDR:     "NOT IN CIDR",
+	INTERSECTS:    "INTERSECTS",
+	DISJOINT:      "DISJOINT",
+	SUBSETOF:      "SUBSET OF",
+	NOTSUBSETOF:   "NOT SUBSET OF",
+	SUPERSETOF:    "SUPERSET OF",
+	NOTSUPERSETOF: "NOT SUPERSET OF",
+	BETWEEN:       "BETWEEN",
+	NOTBETWEEN:    "NOT BETWEEN",
+
+	ADD: "+",
+	SUB: "-",
+
 	LPAREN: "(",
 	RPAREN: ")",
+	COMMA:  ",",
+	COLON:  ":",
 }
 
 func (tok Token) String() string {
@@ -142,8 +244,15 @@ func (tok Token) precedenceV1() int {
 		return 1
 	case AND, NAND:
 		return 2
-	case EQ, NEQ, LT, LTE, GT, GTE, EREG, NEREG, IN, NOTIN:
+	case EQ, NEQ, LT, LTE, GT, GTE, EREG, NEREG, IN, NOTIN,
+		CONTAINS, NOTCONTAINS, ICONTAINS, NOTICONTAINS, STARTSWITH, NOTSTARTSWITH,
+		ENDSWITH, NOTENDSWITH, LIKE, NOTLIKE, ILIKE, NOTILIKE, EQI, NOTEQI,
+		SATISFIES, NOTSATISFIES, INCIDR, NOTINCIDR,
+		INTERSECTS, DISJOINT, SUBSETOF, NOTSUBSETOF, SUPERSETOF, NOTSUPERSETOF,
+		BETWEEN, NOTBETWEEN:
 		return 3
+	case ADD, SUB:
+		return 4
 	}
 	return 0
 }
@@ -161,6 +270,64 @@ func (tok Token) IsOperator() bool {
 	return tok > operatorBegin && tok < operatorEnd
 }
 
+// IsUnary reports whether tok applies to a single operand
+func (tok Token) IsUnary() bool {
+	return tok > unaryBegin && tok < unaryEnd
+}
+
+// IsQuantifier reports whether tok is ANY, ALL or NONE
+func (tok Token) IsQuantifier() bool {
+	return tok > quantifierBegin && tok < quantifierEnd
+}
+
+// IsPostfix reports whether tok is a unary operator written after its operand
+func (tok Token) IsPostfix() bool {
+	return tok == ISNULL || tok == ISNOTNULL
+}
+
+// Negated operators, e.g. IN and NOT IN
+var negated = map[Token]Token{
+	EQ:         NEQ,
+	EREG:       NEREG,
+	IN:         NOTIN,
+	CONTAINS:   NOTCONTAINS,
+	ICONTAINS:  NOTICONTAINS,
+	STARTSWITH: NOTSTARTSWITH,
+	ENDSWITH:   NOTENDSWITH,
+	LIKE:       NOTLIKE,
+	ILIKE:      NOTILIKE,
+	EQI:        NOTEQI,
+	SATISFIES:  NOTSATISFIES,
+	INCIDR:     NOTINCIDR,
+	INTERSECTS: DISJOINT,
+	SUBSETOF:   NOTSUBSETOF,
+	SUPERSETOF: NOTSUPERSETOF,
+	BETWEEN:    NOTBETWEEN,
+}
+
+// Negate returns the operator giving the opposite result, ILLEGAL if none
+func (tok Token) Negate() Token {
+	if neg, ok := negated[tok]; ok {
+		return neg
+	}
+	for tok2, neg := range negated {
+		if neg == tok {
+			return tok2
+		}
+	}
+	return ILLEGAL
+}
+
+// IsArithmetic reports whether tok computes a value rather than a boolean (+, -)
+func (tok Token) IsArithmetic() bool {
+	return tok > operatorBeginLevel4 && tok < operatorEndLevel4
+}
+
+// IsLogical reports whether tok combines two boolean operands (OR, XOR, AND, NAND)
+func (tok Token) IsLogical() bool {
+	return tok > operatorBeginLevel1 && tok < operatorEndLevel2
+}
+
 func SetVersion(v int) {
 	if _, ok := allowVersions[v]; ok {
 		version = v
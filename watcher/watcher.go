@@ -0,0 +1,341 @@
+package watcher
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/thenam153/conditions-go/ast"
+	lerrors "github.com/thenam153/conditions-go/errors"
+	"github.com/thenam153/conditions-go/parser"
+)
+
+var (
+	DefaultInterval = 2 * time.Second
+	DefaultPattern  = "*.cond"
+)
+
+// A file modified this close to the time it was read may be rewritten again
+// with the same size and modification time, coarse file system timestamps
+// included: its content is compared on every scan.
+const racyWindow = 2 * time.Second
+
+// RuleSet is an immutable snapshot of all rules compiled from a directory.
+// Rules are keyed by file name without extension.
+type RuleSet struct {
+	Rules    map[string]ast.Expr
+	LoadedAt time.Time
+}
+
+func (rs *RuleSet) Get(name string) (ast.Expr, bool) {
+	if rs == nil {
+		return nil, false
+	}
+	expr, ok := rs.Rules[name]
+	return expr, ok
+}
+
+// Names returns sorted rule names of the set
+func (rs *RuleSet) Names() []string {
+	if rs == nil {
+		return nil
+	}
+	names := make([]string, 0, len(rs.Rules))
+	for name := range rs.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Event is emitted after every reload attempt. Err is nil when the new rule
+// set was swapped in, otherwise the previous rule set stays active.
+type Event struct {
+	RuleSet *RuleSet
+	Changed []string
+	Err     error
+	Time    time.Time
+}
+
+type Option func(*Watcher)
+
+// WithInterval sets the polling interval of the watcher
+func WithInterval(d time.Duration) Option {
+	return func(w *Watcher) {
+		if d > 0 {
+			w.interval = d
+		}
+	}
+}
+
+// WithPattern sets the glob pattern (filepath.Match) used to select rule files
+func WithPattern(pattern string) Option {
+	return func(w *Watcher) {
+		w.pattern = pattern
+	}
+}
+
+// WithCallback registers a function called synchronously after every reload attempt
+func WithCallback(fn func(Event)) Option {
+	return func(w *Watcher) {
+		w.callback = fn
+	}
+}
+
+// WithEventBuffer sets the size of the events channel, events are dropped
+// when the channel is full
+func WithEventBuffer(n int) Option {
+	return func(w *Watcher) {
+		if n >= 0 {
+			w.events = make(chan Event, n)
+		}
+	}
+}
+
+// WithParserOptions parses rule files with opts, e.g. to declare JQ
+// variables and functions shared by the rules
+func WithParserOptions(opts ...parser.Option) Option {
+	return func(w *Watcher) {
+		w.parserOptions = append(w.parserOptions, opts...)
+	}
+}
+
+// Watcher polls a rules directory and atomically swaps the active rule set
+// once every rule file has been parsed successfully.
+type Watcher struct {
+	dir      string
+	pattern  string
+	interval time.Duration
+	callback func(Event)
+	events   chan Event
+
+	parserOptions []parser.Option
+
+	current atomic.Pointer[RuleSet]
+
+	mu      sync.Mutex
+	files   map[string]ruleFile
+	lastErr string
+
+	started  atomic.Bool
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+type ruleFile struct {
+	modTime time.Time
+	size    int64
+	sum     [sha256.Size]byte
+	readAt  time.Time
+	expr    ast.Expr
+}
+
+// NewWatcher loads every rule file of dir, an error is returned when the
+// initial rule set cannot be compiled.
+func NewWatcher(dir string, opts ...Option) (*Watcher, error) {
+	w := &Watcher{
+		dir:      dir,
+		pattern:  DefaultPattern,
+		interval: DefaultInterval,
+		events:   make(chan Event, 16),
+		files:    map[string]ruleFile{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if _, err := filepath.Match(w.pattern, ""); err != nil {
+		return nil, lerrors.NewWrap("Invalid rule file pattern", err)
+	}
+	if err := w.Reload(); err != nil {
+		return nil, lerrors.NewWrap("Cannot load rule set", err)
+	}
+	return w, nil
+}
+
+// Rules returns the active rule set
+func (w *Watcher) Rules() *RuleSet {
+	return w.current.Load()
+}
+
+// Events returns the channel receiving reload events. Events are dropped
+// when the channel is full.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Start polls the directory in a new goroutine until Stop is called. The
+// errors of these reloads are only reported through the callback and the
+// events channel.
+func (w *Watcher) Start() {
+	if !w.started.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.Reload()
+			}
+		}
+	}()
+}
+
+// Stop stops polling and waits for the running reload to finish
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	if w.started.Load() {
+		<-w.done
+	}
+}
+
+// Reload re-parses changed rule files and swaps the active rule set when all
+// of them compile. A file whose modification time and size did not change is
+// assumed unchanged, unless it was modified shortly before it was last read;
+// a file rewritten with the same size and an older modification time set
+// back by hand is missed. A file touched without a change of content is not
+// re-parsed. Nothing is swapped and no event is emitted when no file
+// changed and the previous attempt succeeded. Events are emitted after the
+// watcher is unlocked, a callback may call Reload.
+func (w *Watcher) Reload() error {
+	event, err := w.reload()
+	if event != nil {
+		w.emit(*event)
+	}
+	return err
+}
+
+func (w *Watcher) reload() (*Event, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	files, changed, err := w.scan()
+	if err == nil && len(changed) == 0 && w.current.Load() != nil && w.lastErr == "" {
+		return nil, nil
+	}
+	// Report a broken rule set only once until it changes
+	if err != nil && err.Error() == w.lastErr {
+		return nil, err
+	}
+	event := &Event{Changed: changed, Err: err, Time: time.Now()}
+	if err == nil {
+		rs := &RuleSet{Rules: make(map[string]ast.Expr, len(files)), LoadedAt: event.Time}
+		for path, f := range files {
+			rs.Rules[ruleName(path)] = f.expr
+		}
+		w.files = files
+		w.lastErr = ""
+		w.current.Store(rs)
+		event.RuleSet = rs
+	} else {
+		w.lastErr = err.Error()
+		event.RuleSet = w.current.Load()
+	}
+	return event, err
+}
+
+func (w *Watcher) emit(event Event) {
+	if w.callback != nil {
+		w.callback(event)
+	}
+	select {
+	case w.events <- event:
+	default:
+	}
+}
+
+// Scan rule directory, parse changed files and return the new file table
+func (w *Watcher) scan() (map[string]ruleFile, []string, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, nil, lerrors.NewWrap("Cannot read rule directory", err)
+	}
+	var (
+		files   = make(map[string]ruleFile, len(entries))
+		changed []string
+		errs    []error
+		names   = map[string]string{}
+	)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if ok, _ := filepath.Match(w.pattern, entry.Name()); !ok {
+			continue
+		}
+		path := filepath.Join(w.dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			errs = append(errs, lerrors.NewWrap(path, err))
+			continue
+		}
+		name := ruleName(path)
+		if other, ok := names[name]; ok {
+			errs = append(errs, lerrors.Newf("Duplicate rule name %v in %v and %v", name, other, path))
+			continue
+		}
+		names[name] = path
+		old, ok := w.files[path]
+		if ok && old.modTime.Equal(info.ModTime()) && old.size == info.Size() && old.readAt.Sub(old.modTime) > racyWindow {
+			files[path] = old
+			continue
+		}
+		readAt := time.Now()
+		src, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, lerrors.NewWrap(path, lerrors.NewWrap("Cannot read rule file", err)))
+			continue
+		}
+		sum := sha256.Sum256(src)
+		if ok && sum == old.sum {
+			files[path] = ruleFile{modTime: info.ModTime(), size: info.Size(), sum: sum, readAt: readAt, expr: old.expr}
+			continue
+		}
+		expr, err := parseRule(src, w.parserOptions)
+		if err != nil {
+			errs = append(errs, lerrors.NewWrap(path, err))
+			continue
+		}
+		files[path] = ruleFile{modTime: info.ModTime(), size: info.Size(), sum: sum, readAt: readAt, expr: expr}
+		changed = append(changed, path)
+	}
+	for path := range w.files {
+		if _, ok := files[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	if len(errs) > 0 {
+		return nil, changed, lerrors.Join(errs...)
+	}
+	return files, changed, nil
+}
+
+func parseRule(src []byte, opts []parser.Option) (ast.Expr, error) {
+	if strings.TrimSpace(string(src)) == "" {
+		return nil, lerrors.New("Rule file is empty")
+	}
+	expr, err := parser.NewParser(strings.NewReader(string(src)), opts...).Parse()
+	if err != nil {
+		return nil, lerrors.NewWrap("Cannot parse rule file", err)
+	}
+	return expr, nil
+}
+
+func ruleName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
@@ -0,0 +1,212 @@
+package watcher_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thenam153/conditions-go/watcher"
+)
+
+func writeRule(t *testing.T, dir, name, src string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadCycles(t *testing.T) {
+	dir := t.TempDir()
+	writeRule(t, dir, "a.cond", `[a] == 1`)
+	w, err := watcher.NewWatcher(dir, watcher.WithEventBuffer(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The initial load
+	<-w.Events()
+
+	steps := []struct {
+		name   string
+		change func()
+		// No event expected
+		silent  bool
+		wantErr bool
+		rules   []string
+	}{
+		{
+			name:   "unchanged",
+			change: func() {},
+			silent: true,
+		},
+		{
+			name:   "add",
+			change: func() { writeRule(t, dir, "b.cond", `[b] == 2`) },
+			rules:  []string{"a", "b"},
+		},
+		{
+			name:    "break",
+			change:  func() { writeRule(t, dir, "b.cond", `[b] ==`) },
+			wantErr: true,
+			rules:   []string{"a", "b"},
+		},
+		{
+			name:   "still broken",
+			change: func() {},
+			silent: true,
+		},
+		{
+			name:   "fix",
+			change: func() { writeRule(t, dir, "b.cond", `[b] == 3`) },
+			rules:  []string{"a", "b"},
+		},
+		{
+			name:    "add broken",
+			change:  func() { writeRule(t, dir, "c.cond", `[c] ==`) },
+			wantErr: true,
+			rules:   []string{"a", "b"},
+		},
+		{
+			name: "delete broken",
+			change: func() {
+				if err := os.Remove(filepath.Join(dir, "c.cond")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			rules: []string{"a", "b"},
+		},
+		{
+			name:    "add same broken again",
+			change:  func() { writeRule(t, dir, "c.cond", `[c] ==`) },
+			wantErr: true,
+			rules:   []string{"a", "b"},
+		},
+		{
+			name: "delete",
+			change: func() {
+				for _, name := range []string{"b.cond", "c.cond"} {
+					if err := os.Remove(filepath.Join(dir, name)); err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+			rules: []string{"a"},
+		},
+	}
+	for _, step := range steps {
+		step.change()
+		err := w.Reload()
+		if (err != nil) != step.wantErr && !step.silent {
+			t.Fatalf("%s: got error %v, want error %v", step.name, err, step.wantErr)
+		}
+		select {
+		case event := <-w.Events():
+			if step.silent {
+				t.Fatalf("%s: unexpected event %+v", step.name, event)
+			}
+			if (event.Err != nil) != step.wantErr {
+				t.Fatalf("%s: got event error %v, want error %v", step.name, event.Err, step.wantErr)
+			}
+			if got := event.RuleSet.Names(); !equal(got, step.rules) {
+				t.Fatalf("%s: got rules %v, want %v", step.name, got, step.rules)
+			}
+		default:
+			if !step.silent {
+				t.Fatalf("%s: no event", step.name)
+			}
+		}
+	}
+}
+
+func TestCallbackReloads(t *testing.T) {
+	dir := t.TempDir()
+	writeRule(t, dir, "a.cond", `[a] == 1`)
+	var w *watcher.Watcher
+	calls := 0
+	w, err := watcher.NewWatcher(dir, watcher.WithCallback(func(watcher.Event) {
+		calls++
+		if w != nil {
+			// Deadlocks when the callback runs with the watcher locked
+			w.Reload()
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeRule(t, dir, "a.cond", `[a] == 2`)
+	done := make(chan error)
+	go func() { done <- w.Reload() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Reload from the callback deadlocked")
+	}
+	if calls != 2 {
+		t.Fatalf("got %d callback calls, want 2", calls)
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestReloadSameSizeAndTime(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.cond")
+	if err := os.WriteFile(path, []byte(`[a] == 1`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := watcher.NewWatcher(dir, watcher.WithEventBuffer(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-w.Events()
+
+	// Rewritten right after it was read, with the same size and time
+	if err := os.WriteFile(path, []byte(`[a] == 2`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-w.Events():
+		if !equal(event.Changed, []string{path}) {
+			t.Fatalf("got changed files %v, want %v", event.Changed, path)
+		}
+	default:
+		t.Fatal("same size rewrite missed")
+	}
+
+	// Touched only
+	mtime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-w.Events():
+		t.Fatalf("unexpected event %+v", event)
+	default:
+	}
+}